@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"log"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// resolves the dependency timeout of every operation against the global one
+// operations after a waiting restore get at least the restore time of its tier
+// configuring a shorter "dependencytimeout" on such an operation is rejected
+func getDependencyTimeouts(
+	operations map[string]types.RuntimeOperationSingle,
+	dependencies map[string][]string,
+	global types.DependencyTimeout,
+) (map[string]types.DependencyTimeout, error) {
+	timeouts := make(map[string]types.DependencyTimeout, len(operations))
+
+	for name, operation := range operations {
+		timeout := operation.GetDependencyTimeout().Inherit(global)
+
+		for _, dependency := range dependencies[name] {
+			waiting, ok := operations[dependency].(types.WaitingOperation)
+			if !ok || waiting.MaxWait() <= timeout.Base {
+				continue
+			}
+
+			if operation.GetDependencyTimeout().Base != 0 {
+				return nil, fmt.Errorf("\"dependencytimeout\" of operation %q is shorter than the restore wait of %s of its dependency %q", name, waiting.MaxWait(), dependency)
+			}
+			log.Printf("raising dependency timeout of operation %q to the restore wait of %s of %q", name, waiting.MaxWait(), dependency)
+			timeout.Base = waiting.MaxWait()
+		}
+
+		timeouts[name] = timeout
+	}
+
+	return timeouts, nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+func TestGetDependencyTimeouts(test *testing.T) {
+	// arrange
+	global := types.DependencyTimeout{Base: time.Hour}
+	testTabel := []struct {
+		name       string
+		operations string
+		expected   time.Duration
+		err        string
+	}{
+		{
+			name: "restore without wait",
+			operations: `[
+				{"name": "restore", "action": "restore", "source": "raw", "tier": "Bulk"},
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "dependson": ["restore"]}
+			]`,
+			expected: time.Hour,
+		},
+		{
+			name: "waiting expedited restore",
+			operations: `[
+				{"name": "restore", "action": "restore", "source": "raw", "tier": "Expedited", "wait": true},
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "dependson": ["restore"]}
+			]`,
+			expected: time.Hour,
+		},
+		{
+			name: "waiting bulk restore",
+			operations: `[
+				{"name": "restore", "action": "restore", "source": "raw", "tier": "Bulk", "wait": true, "pollinterval": "10m"},
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "dependson": ["restore"]}
+			]`,
+			expected: 48*time.Hour + 10*time.Minute,
+		},
+		{
+			name: "transitive dependency of waiting restore",
+			operations: `[
+				{"name": "restore", "action": "restore", "source": "raw", "wait": true},
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "dependson": ["restore"]},
+				{"name": "backup", "action": "copy", "source": "archive", "target": "backup"}
+			]`,
+			expected: 12*time.Hour + time.Minute,
+		},
+		{
+			name: "configured timeout long enough",
+			operations: `[
+				{"name": "restore", "action": "restore", "source": "raw", "wait": true},
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "dependson": ["restore"], "dependencytimeout": "24h"}
+			]`,
+			expected: 24 * time.Hour,
+		},
+		{
+			name: "configured timeout too short",
+			operations: `[
+				{"name": "restore", "action": "restore", "source": "raw", "wait": true},
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "dependson": ["restore"], "dependencytimeout": "2h"}
+			]`,
+			err: `"dependencytimeout" of operation "copy" is shorter than the restore wait of 12h1m0s of its dependency "restore"`,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			_, operations, _, err := parseTestConfig(subtest.operations)
+			if err != nil {
+				test.Fatalf("test %q failed for %s", subtest.name, err)
+			}
+			dependencies, err := getOperationDependencies(operations)
+			if err != nil {
+				test.Fatalf("test %q failed for %s", subtest.name, err)
+			}
+
+			// act
+			timeouts, err := getDependencyTimeouts(operations, dependencies, global)
+
+			// assert
+			if subtest.err != "" {
+				if err == nil || err.Error() != subtest.err {
+					test.Errorf("test %q failed for %v != %s", subtest.name, err, subtest.err)
+				}
+				return
+			}
+			if err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			for name, timeout := range timeouts {
+				if name == "restore" {
+					continue
+				}
+				if timeout.Base != subtest.expected {
+					test.Errorf("test %q failed for %q: %s != %s", subtest.name, name, timeout.Base, subtest.expected)
+				}
+			}
+		})
+	}
+}
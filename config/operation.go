@@ -51,6 +51,12 @@ func getOperations(rawConfig *viper.Viper, resources map[string]types.RuntimeRes
 
 		s := operation.GetOperationSource()
 		sources[s.GetResourceName()] = s // duplicate sources are fine here
+		// resources compared by excludes (e.g. exists_in) are collected as well
+		if referrer, ok := operation.(types.ResourceReferrer); ok {
+			for _, resource := range referrer.ReferencedResources() {
+				sources[resource.GetResourceName()] = resource
+			}
+		}
 
 		// ensure name uniqness
 		if _, ok := operations[operation.GetOperationName()]; ok {
@@ -60,18 +66,5 @@ func getOperations(rawConfig *viper.Viper, resources map[string]types.RuntimeRes
 		operations[operation.GetOperationName()] = operation
 	}
 
-	// if target of one operation is source of another we get race conditions
-	// more complex dependency traversal may be viable in the future
-	for operationName, operation := range operations {
-		opeartionDouble, ok := operation.(types.RuntimeOperationDouble)
-		if !ok {
-			continue
-		}
-
-		if _, ok := sources[opeartionDouble.GetOperationTarget().GetResourceName()]; ok {
-			return nil, nil, fmt.Errorf("source %q is target es well", operationName)
-		}
-	}
-
 	return operations, sources, nil
 }
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// operation "from" has to complete a partition before operation "to" may work on it
+type operationEdge struct {
+	from   string
+	to     string
+	reason string
+}
+
+// builds the operation graph from explicit "dependson" and implicit source/target relations
+// and returns the transitive prerequisites of every operation
+func getOperationDependencies(operations map[string]types.RuntimeOperationSingle) (map[string][]string, error) {
+	edges := make(map[string][]operationEdge, len(operations))
+	addEdge := func(from string, to string, reason string) {
+		for _, edge := range edges[to] {
+			if edge.from == from {
+				return
+			}
+		}
+		edges[to] = append(edges[to], operationEdge{from: from, to: to, reason: reason})
+	}
+
+	for name, operation := range operations {
+		for _, dependency := range operation.GetDependencies() {
+			if _, ok := operations[dependency]; !ok {
+				return nil, fmt.Errorf("operation %q assumed dependency %q which does not exist", name, dependency)
+			}
+			addEdge(dependency, name, "dependson")
+		}
+
+		source := operation.GetOperationSource().GetResourceName()
+		for otherName, other := range operations {
+			if otherName == name {
+				continue
+			}
+
+			// data has to be written before it is read
+			if written, ok := writtenResource(other); ok {
+				for _, read := range readResources(operation) {
+					if read == written {
+						addEdge(otherName, name, fmt.Sprintf("reads %q written by it", read))
+					}
+				}
+			}
+
+			// data has to be read before it is removed
+			if types.IsSourceRemover(operation) && !types.IsSourceRemover(other) {
+				for _, read := range readResources(other) {
+					if read == source {
+						addEdge(otherName, name, fmt.Sprintf("removes source %q read by it", source))
+					}
+				}
+			}
+
+			// a move has to copy the partitions before a plain removal of the same source deletes them
+			if types.IsSourceRemover(operation) && types.IsSourceRemover(other) && other.GetOperationSource().GetResourceName() == source {
+				_, moves := operation.(types.RuntimeOperationDouble)
+				_, otherMoves := other.(types.RuntimeOperationDouble)
+				switch {
+				case otherMoves && !moves:
+					addEdge(otherName, name, fmt.Sprintf("removes source %q moved by it", source))
+				case moves == otherMoves && name < otherName:
+					// e.g. disjoint deletes, ordered by name so they never remove the same partition concurrently
+					addEdge(name, otherName, fmt.Sprintf("removes source %q as well", source))
+				}
+			}
+		}
+	}
+
+	if err := checkOperationCycles(operations, edges); err != nil {
+		return nil, err
+	}
+
+	dependencies := make(map[string][]string, len(operations))
+	for name := range operations {
+		ancestors := make(map[string]struct{})
+		collectAncestors(name, edges, ancestors)
+
+		dependencies[name] = make([]string, 0, len(ancestors))
+		for ancestor := range ancestors {
+			dependencies[name] = append(dependencies[name], ancestor)
+		}
+		sort.Strings(dependencies[name])
+	}
+
+	return dependencies, nil
+}
+
+// the source and for read only operations (e.g. verify) the target as well
+func readResources(operation types.RuntimeOperationSingle) []string {
+	resources := []string{operation.GetOperationSource().GetResourceName()}
+	if double, ok := operation.(types.RuntimeOperationDouble); ok && types.IsReadOnly(operation) {
+		resources = append(resources, double.GetOperationTarget().GetResourceName())
+	}
+
+	return resources
+}
+
+func writtenResource(operation types.RuntimeOperationSingle) (string, bool) {
+	double, ok := operation.(types.RuntimeOperationDouble)
+	if !ok || types.IsReadOnly(operation) {
+		return "", false
+	}
+
+	return double.GetOperationTarget().GetResourceName(), true
+}
+
+// the graph is acyclic when this is called
+func collectAncestors(name string, edges map[string][]operationEdge, ancestors map[string]struct{}) {
+	for _, edge := range edges[name] {
+		if _, ok := ancestors[edge.from]; ok {
+			continue
+		}
+		ancestors[edge.from] = struct{}{}
+		collectAncestors(edge.from, edges, ancestors)
+	}
+}
+
+// depth first search reporting the first cycle found including why every edge exists
+func checkOperationCycles(operations map[string]types.RuntimeOperationSingle, edges map[string][]operationEdge) error {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int, len(operations))
+	var path []operationEdge
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		state[name] = inProgress
+		for _, edge := range edges[name] {
+			path = append(path, edge)
+			switch state[edge.from] {
+			case inProgress:
+				return cycleError(edge.from, path)
+			case unvisited:
+				if err := visit(edge.from); err != nil {
+					return err
+				}
+			}
+			path = path[:len(path)-1]
+		}
+		state[name] = done
+		return nil
+	}
+
+	// sorted so the reported cycle is stable between runs
+	names := make([]string, 0, len(operations))
+	for name := range operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			if err := visit(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// path holds the edges walked backwards from dependent to prerequisite, the cycle starts where start was entered
+func cycleError(start string, path []operationEdge) error {
+	first := 0
+	for idx, edge := range path {
+		if edge.to == start {
+			first = idx
+			break
+		}
+	}
+
+	steps := []string{fmt.Sprintf("%q", start)}
+	for idx := first; idx < len(path); idx++ {
+		steps = append(steps, fmt.Sprintf("depends on %q (%s)", path[idx].from, path[idx].reason))
+	}
+
+	return fmt.Errorf("operations have a dependency cycle: %s", strings.Join(steps, " "))
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	pp "smartclip.de/cloud-cleaner/providers"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// parses resources and operations like the setup does, providers are left uninitialized
+func parseTestConfig(rawOperations string) (map[string]types.RuntimeResource, map[string]types.RuntimeOperationSingle, map[string]types.RuntimeResource, error) {
+	rawConfig := viper.New()
+	rawConfig.SetConfigType("json")
+	err := rawConfig.ReadConfig(strings.NewReader(fmt.Sprintf(`{
+		"resources": [
+			{"name": "raw", "provider": "s3", "prefix": "s3://bucket/raw/", "partitionspec": [{"name": "dt", "datatype": "date"}]},
+			{"name": "archive", "provider": "s3", "prefix": "s3://bucket/archive/", "partitionspec": [{"name": "dt", "datatype": "date"}]},
+			{"name": "backup", "provider": "s3", "prefix": "s3://bucket/backup/", "partitionspec": [{"name": "dt", "datatype": "date"}]},
+			{"name": "table", "provider": "trino", "table": "hive.raw.events", "partitionspec": [{"name": "dt", "datatype": "date"}]}
+		],
+		"operations": %s
+	}`, rawOperations)))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	providers := map[string]types.PartitionProvider{"s3": &pp.S3HiveProvider{}, "trino": &pp.TrinoClient{}}
+	resources, err := getResources(rawConfig, providers)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	operations, sources, err := getOperations(rawConfig, resources)
+
+	return resources, operations, sources, err
+}
+
+func TestGetOperationDependencies(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name       string
+		operations string
+		expected   map[string][]string
+		err        string
+	}{
+		{
+			name: "explicit dependency",
+			operations: `[
+				{"name": "a", "action": "tag", "source": "raw", "tags": {"a": "b"}},
+				{"name": "b", "action": "tag", "source": "archive", "tags": {"a": "b"}, "dependson": ["a"]}
+			]`,
+			expected: map[string][]string{"a": {}, "b": {"a"}},
+		},
+		{
+			name: "unknown dependency",
+			operations: `[
+				{"name": "a", "action": "tag", "source": "raw", "tags": {"a": "b"}, "dependson": ["missing"]}
+			]`,
+			err: `operation "a" assumed dependency "missing" which does not exist`,
+		},
+		{
+			name: "written before read and read before removed",
+			operations: `[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive"},
+				{"name": "verify", "action": "verify", "source": "raw", "target": "archive"},
+				{"name": "backup", "action": "copy", "source": "archive", "target": "backup"},
+				{"name": "delete", "action": "delete", "source": "raw"}
+			]`,
+			expected: map[string][]string{
+				"copy":   {},
+				"verify": {"copy"},
+				"backup": {"copy"},
+				"delete": {"copy", "verify"},
+			},
+		},
+		{
+			name: "move before delete of the same source",
+			operations: `[
+				{"name": "delete", "action": "delete", "source": "raw"},
+				{"name": "move", "action": "move", "source": "raw", "target": "archive"}
+			]`,
+			expected: map[string][]string{"delete": {"move"}, "move": {}},
+		},
+		{
+			name: "two moves of the same source",
+			operations: `[
+				{"name": "a", "action": "move", "source": "raw", "target": "archive"},
+				{"name": "b", "action": "move", "source": "raw", "target": "backup"}
+			]`,
+			expected: map[string][]string{"a": {}, "b": {"a"}},
+		},
+		{
+			name: "two deletes of the same source",
+			operations: `[
+				{"name": "a", "action": "delete", "source": "raw"},
+				{"name": "b", "action": "delete", "source": "raw"}
+			]`,
+			expected: map[string][]string{"a": {}, "b": {"a"}},
+		},
+		{
+			name: "cycle with reasons",
+			operations: `[
+				{"name": "a", "action": "copy", "source": "raw", "target": "archive"},
+				{"name": "b", "action": "copy", "source": "archive", "target": "raw"}
+			]`,
+			err: `operations have a dependency cycle: "a" depends on "b" (reads "raw" written by it) depends on "a" (reads "archive" written by it)`,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			_, operations, _, err := parseTestConfig(subtest.operations)
+			if err != nil {
+				test.Fatalf("test %q failed for %s", subtest.name, err)
+			}
+
+			// act
+			dependencies, err := getOperationDependencies(operations)
+
+			// assert
+			if subtest.err != "" {
+				if err == nil || err.Error() != subtest.err {
+					test.Errorf("test %q failed for %v != %s", subtest.name, err, subtest.err)
+				}
+				return
+			}
+			if err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			if !reflect.DeepEqual(dependencies, subtest.expected) {
+				test.Errorf("test %q failed for %v != %v", subtest.name, dependencies, subtest.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGetOperations(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name       string
+		operations string
+		err        string
+	}{
+		{
+			name:       "known storage class",
+			operations: `[{"name": "archive", "action": "transition", "source": "raw", "storageclass": "GLACIER"}]`,
+		},
+		{
+			name:       "unknown storage class",
+			operations: `[{"name": "archive", "action": "transition", "source": "raw", "storageclass": "GLACIAR"}]`,
+			err:        `"storageclass" field of operation "archive" is none of`,
+		},
+		{
+			name:       "lower case storage class",
+			operations: `[{"name": "archive", "action": "transition", "source": "raw", "storageclass": "glacier"}]`,
+			err:        `"storageclass" field of operation "archive" is none of`,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			_, _, _, err := parseTestConfig(subtest.operations)
+
+			// assert
+			if subtest.err == "" && err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			if subtest.err != "" && (err == nil || !strings.Contains(err.Error(), subtest.err)) {
+				test.Errorf("test %q failed for %v does not contain %s", subtest.name, err, subtest.err)
+			}
+		})
+	}
+}
+
+func TestGetOperationSources(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name       string
+		operations string
+		expected   []string
+	}{
+		{
+			name:       "operation source only",
+			operations: `[{"name": "delete", "action": "delete", "source": "raw"}]`,
+			expected:   []string{"raw"},
+		},
+		{
+			name: "resources of exists in exclude",
+			operations: `[{"name": "delete", "action": "delete", "source": "raw", "exclude": [
+				{"kind": "exists_in", "resources": ["archive", "table"]}
+			]}]`,
+			expected: []string{"archive", "raw", "table"},
+		},
+		{
+			name: "resources of nested exists in exclude",
+			operations: `[{"name": "delete", "action": "delete", "source": "raw", "exclude": [
+				{"kind": "not", "exclude": {"kind": "any", "excludes": [
+					{"kind": "exists_in", "resources": ["backup"]}
+				]}}
+			]}]`,
+			expected: []string{"backup", "raw"},
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			_, _, sources, err := parseTestConfig(subtest.operations)
+
+			// assert
+			if err != nil {
+				test.Fatalf("test %q failed for %s", subtest.name, err)
+			}
+			names := make([]string, 0, len(sources))
+			for name := range sources {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, subtest.expected) {
+				test.Errorf("test %q failed for %v != %v", subtest.name, names, subtest.expected)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
@@ -11,7 +12,7 @@ import (
 	"smartclip.de/cloud-cleaner/types"
 )
 
-func getPartitionProviders(conf *viper.Viper) (map[string]types.PartitionProvider, error) {
+func getPartitionProviders(ctx context.Context, conf *viper.Viper) (map[string]types.PartitionProvider, error) {
 	var (
 		providerTemplate types.PartitionProvider
 		wg               sync.WaitGroup
@@ -49,7 +50,7 @@ func getPartitionProviders(conf *viper.Viper) (map[string]types.PartitionProvide
 
 		// get config and initialize the provider
 		wg.Add(1)
-		go providerTemplate.Init(providerSpec, initErrChan, &wg)
+		go providerTemplate.Init(ctx, providerSpec, initErrChan, &wg)
 
 		// store provider for later reference
 		providers[baseProvider.Name] = providerTemplate
@@ -60,8 +61,8 @@ func getPartitionProviders(conf *viper.Viper) (map[string]types.PartitionProvide
 		defer close(initErrChan)
 		wg.Wait()
 	}()
-	// this will either unblock because of some error or because channel close (all completed)
-	if err := <-initErrChan; err != nil {
+	// drain every init error (a provider may report several) until all of them completed
+	if err := types.CollectErrors(initErrChan); err != nil {
 		return nil, err
 	}
 
@@ -69,7 +70,7 @@ func getPartitionProviders(conf *viper.Viper) (map[string]types.PartitionProvide
 	checkErrChan := make(chan error)
 	for _, provider := range providers {
 		wg.Add(1)
-		go provider.CheckAccess(checkErrChan, &wg)
+		go provider.CheckAccess(ctx, checkErrChan, &wg)
 	}
 	// wait for access checks
 	go func() {
@@ -77,7 +78,7 @@ func getPartitionProviders(conf *viper.Viper) (map[string]types.PartitionProvide
 
 		wg.Wait()
 	}()
-	if err := <-checkErrChan; err != nil {
+	if err := types.CollectErrors(checkErrChan); err != nil {
 		return nil, err
 	}
 
@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 
 	"github.com/spf13/viper"
@@ -106,7 +107,7 @@ func getPartitionSpec(rawPartitions []interface{}) ([]types.PartitionSpec, error
 	return runtimePartitions, nil
 }
 
-func Setup() (runtimeConfig RuntimeConfig, err error) {
+func Setup(ctx context.Context) (runtimeConfig RuntimeConfig, err error) {
 	// var rawConfig RawConfig
 	var rawConfigBytes []byte
 	if err = getRawConfig(&rawConfigBytes); err != nil {
@@ -116,13 +117,18 @@ func Setup() (runtimeConfig RuntimeConfig, err error) {
 	viper := viper.New()
 	viper.BindEnv("ProviderConcurrency", "PROVIDERE_CONCURRENCY")
 	viper.SetDefault("ProviderConcurrency", 1)
+	viper.BindEnv("ActionConcurrency", "ACTION_CONCURRENCY")
+	viper.SetDefault("ActionConcurrency", 1)
+	viper.BindEnv("DependencyTimeout", "DEPENDENCY_TIMEOUT")
+	viper.SetDefault("DependencyTimeout", "1h")
+	viper.BindEnv("DependencyTimeoutPerGB", "DEPENDENCY_TIMEOUT_PER_GB")
 	viper.BindEnv("S3ListingChunkSize", "S3_LISTING_CHUNCK_SIZE")
 	viper.SetDefault("S3ListingChunkSize", int32(1000))
 
 	viper.SetConfigType("json")
 	viper.ReadConfig(bytes.NewBuffer(rawConfigBytes))
 
-	runtimeConfig, err = getRuntimeConfig(viper)
+	runtimeConfig, err = getRuntimeConfig(ctx, viper)
 	if err != nil {
 		return
 	}
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
 
 	"github.com/spf13/viper"
@@ -9,9 +11,24 @@ import (
 	"smartclip.de/cloud-cleaner/types"
 )
 
+const (
+	RunMode   = "run"   // prepare and (if armed) execute in one go
+	PlanMode  = "plan"  // only write prepared actions to the plan file
+	ApplyMode = "apply" // execute exactly what the plan file contains
+)
+
 type GlobalConfig struct {
 	Armed               bool
+	Mode                string
+	PlanFile            string
+	JournalFile         string
+	Resume              bool
+	ReportFile          string
+	OverrideGuards      bool
 	ProviderConcurrency int
+	ActionConcurrency   int
+	RetryPolicy         types.RetryPolicy
+	DependencyTimeout   types.DependencyTimeout
 }
 
 type RuntimeConfig struct {
@@ -19,13 +36,25 @@ type RuntimeConfig struct {
 	Resources  map[string]types.RuntimeResource
 	Operations map[string]types.RuntimeOperationSingle
 	Sources    map[string]types.RuntimeResource
+	// all direct and transitive prerequisites of every operation
+	Dependencies map[string][]string
+	// effective dependency timeout of every operation
+	DependencyTimeouts map[string]types.DependencyTimeout
 	GlobalConfig
 }
 
-func getRuntimeConfig(rawConfig *viper.Viper) (conf RuntimeConfig, err error) {
-	if conf.Providers, err = getPartitionProviders(rawConfig); err != nil {
+func getRuntimeConfig(ctx context.Context, rawConfig *viper.Viper) (conf RuntimeConfig, err error) {
+	if conf.Providers, err = getPartitionProviders(ctx, rawConfig); err != nil {
+		return
+	}
+
+	// providers and operations only override single fields of the global retry policy
+	if conf.RetryPolicy, err = getRetryPolicy(rawConfig); err != nil {
 		return
 	}
+	for _, provider := range conf.Providers {
+		provider.InheritRetryPolicy(conf.RetryPolicy)
+	}
 
 	if conf.Resources, err = getResources(rawConfig, conf.Providers); err != nil {
 		return
@@ -35,6 +64,10 @@ func getRuntimeConfig(rawConfig *viper.Viper) (conf RuntimeConfig, err error) {
 		return
 	}
 
+	if conf.Dependencies, err = getOperationDependencies(conf.Operations); err != nil {
+		return
+	}
+
 	// force positive Parallelism even if configured differently
 	concurrency := rawConfig.GetInt("ProviderConcurrency")
 	if concurrency < 1 {
@@ -43,9 +76,86 @@ func getRuntimeConfig(rawConfig *viper.Viper) (conf RuntimeConfig, err error) {
 	}
 	conf.ProviderConcurrency = concurrency
 
+	// default worker count of operations not configuring their own
+	concurrency = rawConfig.GetInt("ActionConcurrency")
+	if concurrency < 1 {
+		log.Printf("strange action concurrency value of '%d', sanatizing to '1'", concurrency)
+		concurrency = 1
+	}
+	conf.ActionConcurrency = concurrency
+
+	// operations only override single fields of the global dependency timeout
+	timeoutConf := map[string]interface{}{"dependencytimeout": rawConfig.GetString("DependencyTimeout")}
+	if rawConfig.IsSet("DependencyTimeoutPerGB") {
+		timeoutConf["dependencytimeoutpergb"] = rawConfig.GetString("DependencyTimeoutPerGB")
+	}
+	if conf.DependencyTimeout, err = types.MakeDependencyTimeout(timeoutConf, "global config"); err != nil {
+		return
+	}
+	if conf.DependencyTimeouts, err = getDependencyTimeouts(conf.Operations, conf.Dependencies, conf.DependencyTimeout); err != nil {
+		return
+	}
+
 	armed := flag.Bool("armed", false, "activate configured actions (may cause data loss)")
+	planFile := flag.String("plan", "", "plan file written by mode \"plan\" and executed by mode \"apply\"")
+	journalFile := flag.String("journal", "", "append only journal recording every executed object action")
+	resumeFile := flag.String("resume", "", "journal of an interrupted run, completed actions are skipped")
+	reportFile := flag.String("report", "", "json file receiving the failure report of the run")
+	overrideGuards := flag.Bool("override-guards", false, "run operations even if they exceed their safety guards")
 	flag.Parse()
 
+	// the mode is the first positional argument and may be followed by further flags
+	conf.Mode = RunMode
+	if flag.NArg() > 0 {
+		conf.Mode = flag.Arg(0)
+		if err = flag.CommandLine.Parse(flag.Args()[1:]); err != nil {
+			return
+		}
+	}
+
 	conf.Armed = *armed
+	conf.PlanFile = *planFile
+	conf.JournalFile = *journalFile
+	conf.ReportFile = *reportFile
+	conf.OverrideGuards = *overrideGuards
+	if *resumeFile != "" {
+		if conf.JournalFile != "" && conf.JournalFile != *resumeFile {
+			err = fmt.Errorf("--journal and --resume must refer to the same file when both are set")
+			return
+		}
+		// the resumed journal keeps being appended
+		conf.JournalFile = *resumeFile
+		conf.Resume = true
+	}
+	switch conf.Mode {
+	case RunMode:
+	case PlanMode, ApplyMode:
+		if conf.PlanFile == "" {
+			err = fmt.Errorf("mode %q requires a plan file (--plan)", conf.Mode)
+			return
+		}
+		// applying a reviewed plan is the explicit confirmation
+		conf.Armed = conf.Mode == ApplyMode
+	default:
+		err = fmt.Errorf("unknown mode %q (expected %q, %q or %q)", conf.Mode, RunMode, PlanMode, ApplyMode)
+	}
+
 	return
 }
+
+func getRetryPolicy(rawConfig *viper.Viper) (types.RetryPolicy, error) {
+	if ok := rawConfig.IsSet("retry"); !ok {
+		return types.DefaultRetryPolicy, nil
+	}
+	retryConf, ok := rawConfig.Get("retry").(map[string]interface{})
+	if !ok {
+		return types.RetryPolicy{}, fmt.Errorf("retry config is not a map")
+	}
+
+	policy, err := types.MakeRetryPolicy(retryConf, "global config")
+	if err != nil {
+		return types.RetryPolicy{}, err
+	}
+
+	return policy.Inherit(types.DefaultRetryPolicy), nil
+}
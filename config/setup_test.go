@@ -1,47 +1,86 @@
 package config
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
-func TestSetup(test *testing.T) {
-	type expected struct {
-		bucket string
-		key    string
-	}
-
+func TestGetRawConfig(test *testing.T) {
 	// arrange
 	testTabel := []struct {
 		name     string
-		envs     map[string]string
-		jsonnnet string
-		expected expected
+		file     string
+		input    string
+		expected string
 		err      bool
 	}{
 		{
-			name:     "no prefix partition",
-			prefix:   "s3://bucket/key/hallo/welt",
-			expected: expected{bucket: "bucket", key: "key/hallo/welt"},
+			name: "no file error",
+			file: "archiving_config.jsonnet",
+			err:  true,
+		},
+		{
+			name:  "some broken jsonnet",
+			file:  "archiving_config.jsonnet",
+			input: `{"Resources":[}`,
+			err:   true,
+		},
+		{
+			name: "parse success",
+			file: "archiving_config.jsonnet",
+			input: `{
+        "Resources":[
+          {
+            "Name": "resource",
+            "PartitionProvider": {
+              Name: "s3_hive"
+            },
+            PartitionSpec: {
+              Name: 'day',
+              DataType: 'date',
+            },
+             "Prefix": "s3://prefix"
+          },
+        ]
+      }`,
+			expected: "resource",
+		},
+		{
+			name:     "plain json is not evaluated",
+			file:     "archiving_config.json",
+			input:    `{"Resources": [{"Name": "resource"}]}`,
+			expected: "resource",
 		},
 	}
 
 	for _, subtest := range testTabel {
 		test.Run(subtest.name, func(t *testing.T) {
+			// arrange
+			configFilePath := filepath.Join(t.TempDir(), subtest.file)
+			if len(subtest.input) > 0 {
+				if err := os.WriteFile(configFilePath, []byte(subtest.input), 0644); err != nil {
+					test.Fatalf("test %q could not write config: %s", subtest.name, err)
+				}
+			}
+			t.Setenv("ARCHIVING_CONFIG", configFilePath)
+
 			// act
-			bucket, key, err := splitBucketAndKey(subtest.prefix)
+			var rawConfigBytes []byte
+			err := getRawConfig(&rawConfigBytes)
 
 			// assert
 			if (err == nil) == subtest.err {
 				test.Errorf("%q failed with unexpected error %q", subtest.name, err)
 			}
 
-			if bucket != subtest.expected.bucket || key != subtest.expected.key {
-				test.Errorf(
-					"%q failed with %v != %v",
-					subtest.name,
-					expected{bucket: bucket, key: key},
-					subtest.expected,
-				)
+			if len(subtest.expected) > 0 {
+				// only check without errors to make sure at least one resource entry is in array
+				var conf struct{ Resources []struct{ Name string } }
+				if err := json.Unmarshal(rawConfigBytes, &conf); err != nil || len(conf.Resources) < 1 || conf.Resources[0].Name != subtest.expected {
+					test.Errorf("test %q failed for %s != %v", subtest.name, rawConfigBytes, subtest.expected)
+				}
 			}
 		})
 	}
@@ -0,0 +1,157 @@
+package exclude
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+
+	"smartclip.de/cloud-cleaner/partitions"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+type partitionBound struct {
+	// raw values of the leading partition columns, parsed against the spec of the resource once it is known
+	values    []string
+	inclusive bool
+}
+
+// excludes partitions whose values lie between from and to (half open by default: from inclusive, to exclusive)
+// bounds may name fewer values than the partition has columns, only the leading columns are compared then
+type AbsolutePartitionExclude struct {
+	from *partitionBound
+	to   *partitionBound
+}
+
+func (excludeSpec AbsolutePartitionExclude) IgnorePartition(partitions types.PartitionList) (types.PartitionList, error) {
+	if len(partitions) == 0 {
+		return partitions, nil
+	}
+	sort.Sort(partitions)
+
+	// all partitions of the list belong to the same resource
+	resource := partitions[0].GetResource()
+	if resource == nil {
+		return types.PartitionList{}, fmt.Errorf("absolute partition exclude needs the partition spec of the resource")
+	}
+	from, err := excludeSpec.from.parse(resource)
+	if err != nil {
+		return types.PartitionList{}, err
+	}
+	to, err := excludeSpec.to.parse(resource)
+	if err != nil {
+		return types.PartitionList{}, err
+	}
+
+	keptPartitions := make(types.PartitionList, 0, len(partitions))
+	for _, partition := range partitions {
+		values := partition.GetParsedValues()
+		excludePartition := excludeSpec.from.admits(comparePartitionValues(values, from)) &&
+			excludeSpec.to.admits(-comparePartitionValues(values, to))
+		if !excludePartition {
+			keptPartitions = append(keptPartitions, partition)
+		}
+	}
+	log.Printf("absolute partition exclude from: %v - to: %v keeps %d of %d partitions", excludeSpec.from, excludeSpec.to, len(keptPartitions), len(partitions))
+
+	return keptPartitions, nil
+}
+
+func (bound *partitionBound) parse(resource types.RuntimeResource) (types.TypedPartitionValueList, error) {
+	if bound == nil {
+		return nil, nil
+	}
+
+	spec := resource.GetPartitionSpec()
+	if len(bound.values) > len(spec) {
+		return nil, fmt.Errorf("bound %v has more values than resource %q has partition columns", bound.values, resource.GetResourceName())
+	}
+	parsed, err := partitions.ParsePartitionString(spec[:len(bound.values)], bound.values)
+	if err != nil {
+		return nil, fmt.Errorf("bound %v does not match the partition spec of resource %q: %w", bound.values, resource.GetResourceName(), err)
+	}
+
+	return parsed, nil
+}
+
+// whether a partition comparing to the bound like cmp (1 means beyond the bound) lies inside the range
+func (bound *partitionBound) admits(cmp int) bool {
+	if bound == nil {
+		return true
+	}
+
+	return cmp > 0 || cmp == 0 && bound.inclusive
+}
+
+func (bound *partitionBound) String() string {
+	if bound == nil {
+		return "unbounded"
+	}
+	if bound.inclusive {
+		return fmt.Sprintf("%v (inclusive)", bound.values)
+	}
+
+	return fmt.Sprintf("%v (exclusive)", bound.values)
+}
+
+// compares the leading values column by column, -1 if smaller, 1 if greater and 0 if equal
+func comparePartitionValues(values types.TypedPartitionValueList, bound types.TypedPartitionValueList) int {
+	for idx, boundValue := range bound {
+		if values[idx].Smaller(boundValue) {
+			return -1
+		}
+		if boundValue.Smaller(values[idx]) {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func MakeAbsolutePartitionExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
+	var (
+		err     error
+		exclude AbsolutePartitionExclude
+	)
+
+	if exclude.from, err = makePartitionBound(operationName, conf, "from", true); err != nil {
+		return nil, err
+	}
+	if exclude.to, err = makePartitionBound(operationName, conf, "to", false); err != nil {
+		return nil, err
+	}
+	if exclude.from == nil && exclude.to == nil {
+		return nil, fmt.Errorf("absolute partition exclude of operation %q has neither \"from\" nor \"to\"", operationName)
+	}
+
+	return exclude, nil
+}
+
+func makePartitionBound(operationName string, conf map[string]interface{}, field string, inclusive bool) (*partitionBound, error) {
+	val, ok := conf[field]
+	if !ok {
+		return nil, nil
+	}
+	rawValues, ok := val.([]interface{})
+	if !ok || len(rawValues) == 0 {
+		return nil, fmt.Errorf("%q field in exclude of operation %q is not a non empty array", field, operationName)
+	}
+
+	bound := &partitionBound{values: make([]string, len(rawValues)), inclusive: inclusive}
+	for idx, rawValue := range rawValues {
+		// int columns may be given as json numbers
+		if number, ok := types.ConfigInt(rawValue); ok {
+			bound.values[idx] = strconv.Itoa(number)
+		} else if bound.values[idx], ok = rawValue.(string); !ok {
+			return nil, fmt.Errorf("%q field in exclude of operation %q contains %v which is neither string nor integer", field, operationName, rawValue)
+		}
+	}
+
+	if val, ok := conf[field+"inclusive"]; ok {
+		if bound.inclusive, ok = val.(bool); !ok {
+			return nil, fmt.Errorf("\"%sinclusive\" field in exclude of operation %q is not a boolean", field, operationName)
+		}
+	}
+
+	return bound, nil
+}
@@ -0,0 +1,105 @@
+package exclude
+
+import (
+	"testing"
+
+	"smartclip.de/cloud-cleaner/partitions"
+	"smartclip.de/cloud-cleaner/providers"
+	"smartclip.de/cloud-cleaner/resources"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// partitions of a (dt date, hour int) resource
+func datedPartitions(values ...[2]string) types.PartitionList {
+	spec := []types.PartitionSpec{{Name: "dt", DataType: partitions.Date}, {Name: "hour", DataType: partitions.Int}}
+	resource := &resources.BaseResource{Name: "events", PartitionSpec: spec}
+
+	list := make(types.PartitionList, len(values))
+	for idx := range values {
+		raw := []string{values[idx][0], values[idx][1]}
+		parsed, _ := partitions.ParsePartitionString(spec, raw)
+		list[idx] = &providers.HivePartition{BasePartition: partitions.BasePartition{
+			PartitionValues:      raw,
+			TypedPartitionValues: parsed,
+			Resource:             resource,
+		}}
+	}
+
+	return list
+}
+
+func TestAbsolutePartitionExclude(test *testing.T) {
+	all := func() types.PartitionList {
+		return datedPartitions(
+			[2]string{"2024-01-01", "0"},
+			[2]string{"2024-01-01", "12"},
+			[2]string{"2024-01-02", "0"},
+			[2]string{"2024-01-03", "0"},
+		)
+	}
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		conf     map[string]interface{}
+		expected int
+		fails    bool
+	}{
+		{
+			name:     "half open range of dates",
+			conf:     map[string]interface{}{"from": []interface{}{"2024-01-01"}, "to": []interface{}{"2024-01-02"}},
+			expected: 2,
+		},
+		{
+			name:     "inclusive to",
+			conf:     map[string]interface{}{"from": []interface{}{"2024-01-01"}, "to": []interface{}{"2024-01-02"}, "toinclusive": true},
+			expected: 1,
+		},
+		{
+			name:     "exclusive from on full tuples with json numbers",
+			conf:     map[string]interface{}{"from": []interface{}{"2024-01-01", float64(0)}, "frominclusive": false},
+			expected: 1,
+		},
+		{
+			name:     "open ended to",
+			conf:     map[string]interface{}{"to": []interface{}{"2024-01-01", "12"}},
+			expected: 3,
+		},
+		{
+			name:  "bound not matching the data type",
+			conf:  map[string]interface{}{"from": []interface{}{"yesterday"}},
+			fails: true,
+		},
+		{
+			name:  "too many values",
+			conf:  map[string]interface{}{"from": []interface{}{"2024-01-01", "0", "x"}},
+			fails: true,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			exclude, err := MakeAbsolutePartitionExclude("op", subtest.conf, nil)
+			if err != nil {
+				test.Fatalf("test %q failed for %s", subtest.name, err)
+			}
+
+			// act
+			kept, err := exclude.IgnorePartition(all())
+
+			// assert
+			if subtest.fails {
+				if err == nil {
+					test.Errorf("test %q failed for missing error", subtest.name)
+				}
+				return
+			}
+			if err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			if len(kept) != subtest.expected {
+				test.Errorf("test %q failed for %d != %d", subtest.name, len(kept), subtest.expected)
+			}
+		})
+	}
+}
@@ -36,7 +36,7 @@ func (excludeSpec AbsoluteTimeExclude) IgnorePartition(
 	return keptPartitions, nil
 }
 
-func MakeAbsoluteTimestampExclude(operationName string, conf map[string]interface{}) (types.Exclude, error) {
+func MakeAbsoluteTimestampExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
 	var (
 		err     error
 		exclude AbsoluteTimeExclude
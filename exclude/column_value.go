@@ -0,0 +1,171 @@
+package exclude
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// patterns matched against the raw value of one partition column, any single match is a match of the column
+type columnMatcher struct {
+	column  string
+	regexes []*regexp.Regexp
+	globs   []string
+	values  map[string]bool
+	// the column matches if none of its patterns match
+	negate bool
+}
+
+// excludes partitions whose columns match all configured matchers
+type ColumnValueExclude struct {
+	matchers []columnMatcher
+}
+
+func (excludeSpec ColumnValueExclude) IgnorePartition(partitions types.PartitionList) (types.PartitionList, error) {
+	keptPartitions := make(types.PartitionList, 0, len(partitions))
+	for _, partition := range partitions {
+		excludePartition := true
+		for _, matcher := range excludeSpec.matchers {
+			idx, err := columnIndex(partition, matcher.column)
+			if err != nil {
+				return types.PartitionList{}, err
+			}
+			if !matcher.matches(partition.GetValues()[idx]) {
+				excludePartition = false
+				break
+			}
+		}
+
+		if !excludePartition {
+			keptPartitions = append(keptPartitions, partition)
+		}
+	}
+	log.Printf("column value exclude keeps %d of %d partitions", len(keptPartitions), len(partitions))
+
+	return keptPartitions, nil
+}
+
+func (matcher columnMatcher) matches(value string) bool {
+	return matcher.anyPattern(value) != matcher.negate
+}
+
+func (matcher columnMatcher) anyPattern(value string) bool {
+	if matcher.values[value] {
+		return true
+	}
+	for _, regex := range matcher.regexes {
+		if regex.MatchString(value) {
+			return true
+		}
+	}
+	for _, glob := range matcher.globs {
+		// patterns were validated at creation
+		if matched, _ := path.Match(glob, value); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func MakeColumnValueExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
+	var exclude ColumnValueExclude
+
+	val, ok := conf["columns"]
+	if !ok {
+		return nil, fmt.Errorf("column value exclude of operation %q has no \"columns\"", operationName)
+	}
+	rawColumns, ok := val.(map[string]interface{})
+	if !ok || len(rawColumns) == 0 {
+		return nil, fmt.Errorf("\"columns\" field in exclude of operation %q is not a non empty map", operationName)
+	}
+
+	for column, rawMatcher := range rawColumns {
+		matcherConf, ok := rawMatcher.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("column %q in exclude of operation %q is not a map", column, operationName)
+		}
+		matcher, err := makeColumnMatcher(operationName, column, matcherConf)
+		if err != nil {
+			return nil, err
+		}
+		exclude.matchers = append(exclude.matchers, matcher)
+	}
+	// deterministic evaluation order
+	sort.Slice(exclude.matchers, func(i, j int) bool { return exclude.matchers[i].column < exclude.matchers[j].column })
+
+	return exclude, nil
+}
+
+func makeColumnMatcher(operationName string, column string, conf map[string]interface{}) (columnMatcher, error) {
+	matcher := columnMatcher{column: column, values: make(map[string]bool)}
+
+	values, err := stringList(operationName, column, conf, "values")
+	if err != nil {
+		return columnMatcher{}, err
+	}
+	for _, value := range values {
+		matcher.values[value] = true
+	}
+
+	regexes, err := stringList(operationName, column, conf, "regex")
+	if err != nil {
+		return columnMatcher{}, err
+	}
+	for _, expression := range regexes {
+		regex, err := regexp.Compile(expression)
+		if err != nil {
+			return columnMatcher{}, fmt.Errorf("regex %q of column %q in exclude of operation %q is invalid: %w", expression, column, operationName, err)
+		}
+		matcher.regexes = append(matcher.regexes, regex)
+	}
+
+	if matcher.globs, err = stringList(operationName, column, conf, "glob"); err != nil {
+		return columnMatcher{}, err
+	}
+	for _, glob := range matcher.globs {
+		if _, err := path.Match(glob, ""); err != nil {
+			return columnMatcher{}, fmt.Errorf("glob %q of column %q in exclude of operation %q is invalid: %w", glob, column, operationName, err)
+		}
+	}
+
+	if len(matcher.values)+len(matcher.regexes)+len(matcher.globs) == 0 {
+		return columnMatcher{}, fmt.Errorf("column %q in exclude of operation %q has neither \"values\", \"regex\" nor \"glob\"", column, operationName)
+	}
+
+	if val, ok := conf["negate"]; ok {
+		if matcher.negate, ok = val.(bool); !ok {
+			return columnMatcher{}, fmt.Errorf("\"negate\" of column %q in exclude of operation %q is not a boolean", column, operationName)
+		}
+	}
+
+	return matcher, nil
+}
+
+// a single string or an array of strings
+func stringList(operationName string, column string, conf map[string]interface{}, field string) ([]string, error) {
+	val, ok := conf[field]
+	if !ok {
+		return nil, nil
+	}
+	if str, ok := val.(string); ok {
+		return []string{str}, nil
+	}
+
+	rawList, ok := val.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%q of column %q in exclude of operation %q is neither string nor array", field, column, operationName)
+	}
+	list := make([]string, len(rawList))
+	for idx, rawValue := range rawList {
+		if list[idx], ok = rawValue.(string); !ok {
+			return nil, fmt.Errorf("%q of column %q in exclude of operation %q contains the non string %v", field, column, operationName, rawValue)
+		}
+	}
+
+	return list, nil
+}
@@ -0,0 +1,127 @@
+package exclude
+
+import (
+	"testing"
+
+	"smartclip.de/cloud-cleaner/partitions"
+	"smartclip.de/cloud-cleaner/providers"
+	"smartclip.de/cloud-cleaner/resources"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+func TestColumnValueExclude(test *testing.T) {
+	spec := []types.PartitionSpec{{Name: "country", DataType: partitions.String}, {Name: "source_system", DataType: partitions.String}}
+	resource := &resources.BaseResource{Name: "events", PartitionSpec: spec}
+	all := func() types.PartitionList {
+		var list types.PartitionList
+		for _, values := range [][]string{{"de", "legal_hold_2023"}, {"de", "web"}, {"fr", "legal_hold_2024"}, {"us", "app"}} {
+			parsed, _ := partitions.ParsePartitionString(spec, values)
+			list = append(list, &providers.HivePartition{BasePartition: partitions.BasePartition{
+				PartitionValues:      values,
+				TypedPartitionValues: parsed,
+				Resource:             resource,
+			}})
+		}
+		return list
+	}
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		columns  map[string]interface{}
+		expected int
+	}{
+		{
+			name:     "glob",
+			columns:  map[string]interface{}{"source_system": map[string]interface{}{"glob": "legal_hold_*"}},
+			expected: 2,
+		},
+		{
+			name:     "regex",
+			columns:  map[string]interface{}{"source_system": map[string]interface{}{"regex": []interface{}{"^legal_hold_2024$", "^app$"}}},
+			expected: 2,
+		},
+		{
+			name:     "value list",
+			columns:  map[string]interface{}{"country": map[string]interface{}{"values": []interface{}{"de", "us"}}},
+			expected: 1,
+		},
+		{
+			name:     "negation",
+			columns:  map[string]interface{}{"country": map[string]interface{}{"values": "de", "negate": true}},
+			expected: 2,
+		},
+		{
+			name: "multiple columns must all match",
+			columns: map[string]interface{}{
+				"country":       map[string]interface{}{"values": "de"},
+				"source_system": map[string]interface{}{"glob": "legal_hold_*"},
+			},
+			expected: 3,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			exclude, err := MakeExclude("op", map[string]interface{}{"kind": "column_value", "columns": subtest.columns}, nil)
+			if err != nil {
+				test.Fatalf("test %q failed for %s", subtest.name, err)
+			}
+
+			// act
+			kept, err := exclude.IgnorePartition(all())
+
+			// assert
+			if err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			if len(kept) != subtest.expected {
+				test.Errorf("test %q failed for %d != %d", subtest.name, len(kept), subtest.expected)
+			}
+		})
+	}
+}
+
+func TestColumnValueExcludeErrors(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name    string
+		columns map[string]interface{}
+	}{
+		{
+			name:    "invalid regex",
+			columns: map[string]interface{}{"country": map[string]interface{}{"regex": "("}},
+		},
+		{
+			name:    "invalid glob",
+			columns: map[string]interface{}{"country": map[string]interface{}{"glob": "["}},
+		},
+		{
+			name:    "no pattern",
+			columns: map[string]interface{}{"country": map[string]interface{}{"negate": true}},
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			_, err := MakeColumnValueExclude("op", map[string]interface{}{"columns": subtest.columns}, nil)
+
+			// assert
+			if err == nil {
+				test.Errorf("test %q failed for missing error", subtest.name)
+			}
+		})
+	}
+
+	// unknown columns are only detected against the resource
+	exclude, _ := MakeColumnValueExclude("op", map[string]interface{}{"columns": map[string]interface{}{"region": map[string]interface{}{"values": "eu"}}}, nil)
+	spec := []types.PartitionSpec{{Name: "country", DataType: partitions.String}}
+	partition := &providers.HivePartition{BasePartition: partitions.BasePartition{
+		PartitionValues: []string{"de"},
+		Resource:        &resources.BaseResource{Name: "events", PartitionSpec: spec},
+	}}
+	if _, err := exclude.IgnorePartition(types.PartitionList{partition}); err == nil {
+		test.Errorf("test %q failed for missing error", "unknown column")
+	}
+}
@@ -0,0 +1,251 @@
+package exclude
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// composites nest other exclude specs so they can not be part of the KnownExcludes literal (initialization cycle)
+func init() {
+	KnownExcludes[AllExcludeType] = MakeAllExclude
+	KnownExcludes[AnyExcludeType] = MakeAnyExclude
+	KnownExcludes[NotExcludeType] = MakeNotExclude
+}
+
+type excludeBranch struct {
+	// "name" of the nested spec or its position and kind
+	label   string
+	exclude types.Exclude
+}
+
+// keeps partitions kept by every branch (unlike the sequential exclude list every branch sees all partitions)
+type AllExclude struct {
+	branches []excludeBranch
+}
+
+// keeps partitions kept by at least one branch
+type AnyExclude struct {
+	branches []excludeBranch
+}
+
+// keeps exactly the partitions its branch does not keep
+type NotExclude struct {
+	branch excludeBranch
+}
+
+func (excludeSpec AllExclude) IgnorePartition(partitions types.PartitionList) (types.PartitionList, error) {
+	keptBy, err := keptByBranches(excludeSpec.branches, partitions)
+	if err != nil {
+		return types.PartitionList{}, err
+	}
+
+	var keptPartitions types.PartitionList
+	for _, partition := range partitions {
+		hash := partition.GetParsedValues().ToString()
+		if dropping := missingBranch(excludeSpec.branches, keptBy[hash]); dropping != "" {
+			log.Printf("all exclude drops partition %q (not kept by %s)", hash, dropping)
+			continue
+		}
+		keptPartitions = append(keptPartitions, partition)
+	}
+
+	return sortedPartitions(keptPartitions), nil
+}
+
+func (excludeSpec AnyExclude) IgnorePartition(partitions types.PartitionList) (types.PartitionList, error) {
+	keptBy, err := keptByBranches(excludeSpec.branches, partitions)
+	if err != nil {
+		return types.PartitionList{}, err
+	}
+
+	var keptPartitions types.PartitionList
+	for _, partition := range partitions {
+		hash := partition.GetParsedValues().ToString()
+		if len(keptBy[hash]) == 0 {
+			continue
+		}
+		log.Printf("any exclude keeps partition %q (kept by %v)", hash, keptBy[hash])
+		keptPartitions = append(keptPartitions, partition)
+	}
+
+	return sortedPartitions(keptPartitions), nil
+}
+
+func (excludeSpec NotExclude) IgnorePartition(partitions types.PartitionList) (types.PartitionList, error) {
+	keptBy, err := keptByBranches([]excludeBranch{excludeSpec.branch}, partitions)
+	if err != nil {
+		return types.PartitionList{}, err
+	}
+
+	var keptPartitions types.PartitionList
+	for _, partition := range partitions {
+		hash := partition.GetParsedValues().ToString()
+		if len(keptBy[hash]) > 0 {
+			continue
+		}
+		log.Printf("not exclude keeps partition %q (excluded by %s)", hash, excludeSpec.branch.label)
+		keptPartitions = append(keptPartitions, partition)
+	}
+
+	return sortedPartitions(keptPartitions), nil
+}
+
+func (excludeSpec AllExclude) ReferencedResources() []types.RuntimeResource {
+	return branchResources(excludeSpec.branches)
+}
+
+func (excludeSpec AnyExclude) ReferencedResources() []types.RuntimeResource {
+	return branchResources(excludeSpec.branches)
+}
+
+func (excludeSpec NotExclude) ReferencedResources() []types.RuntimeResource {
+	return branchResources([]excludeBranch{excludeSpec.branch})
+}
+
+func branchResources(branches []excludeBranch) []types.RuntimeResource {
+	excludes := make([]types.Exclude, len(branches))
+	for idx, branch := range branches {
+		excludes[idx] = branch.exclude
+	}
+
+	return types.ReferencedResources(excludes...)
+}
+
+// labels of the branches keeping a partition by partition hash
+// every branch gets its own copy of the list since excludes sort and reuse it
+func keptByBranches(branches []excludeBranch, partitions types.PartitionList) (map[string][]string, error) {
+	keptBy := make(map[string][]string, len(partitions))
+	for _, branch := range branches {
+		branchPartitions := make(types.PartitionList, len(partitions))
+		copy(branchPartitions, partitions)
+
+		kept, err := branch.exclude.IgnorePartition(branchPartitions)
+		if err != nil {
+			return nil, fmt.Errorf("exclude branch %s failed: %w", branch.label, err)
+		}
+		for _, partition := range kept {
+			hash := partition.GetParsedValues().ToString()
+			keptBy[hash] = append(keptBy[hash], branch.label)
+		}
+	}
+
+	return keptBy, nil
+}
+
+// label of the first branch not among the keeping ones, empty if all of them kept the partition
+func missingBranch(branches []excludeBranch, keptBy []string) string {
+	keeping := make(map[string]bool, len(keptBy))
+	for _, label := range keptBy {
+		keeping[label] = true
+	}
+	for _, branch := range branches {
+		if !keeping[branch.label] {
+			return branch.label
+		}
+	}
+
+	return ""
+}
+
+func sortedPartitions(partitions types.PartitionList) types.PartitionList {
+	if partitions == nil {
+		return types.PartitionList{}
+	}
+	sort.Sort(partitions)
+
+	return partitions
+}
+
+func MakeAllExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
+	branches, err := makeExcludeBranches(operationName, conf, AllExcludeType, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	return AllExclude{branches: branches}, nil
+}
+
+func MakeAnyExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
+	branches, err := makeExcludeBranches(operationName, conf, AnyExcludeType, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	return AnyExclude{branches: branches}, nil
+}
+
+func MakeNotExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
+	val, ok := conf["exclude"]
+	if !ok {
+		return nil, fmt.Errorf("%q exclude of operation %q has no nested \"exclude\"", NotExcludeType, operationName)
+	}
+	branchConf, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("nested \"exclude\" of %q exclude of operation %q is not a map", NotExcludeType, operationName)
+	}
+
+	branch, err := makeExcludeBranch(operationName, branchConf, 0, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	return NotExclude{branch: branch}, nil
+}
+
+func makeExcludeBranches(
+	operationName string,
+	conf map[string]interface{},
+	kind types.ExcludeType,
+	resources map[string]types.RuntimeResource,
+) ([]excludeBranch, error) {
+	val, ok := conf["excludes"]
+	if !ok {
+		return nil, fmt.Errorf("%q exclude of operation %q has no nested \"excludes\"", kind, operationName)
+	}
+	rawBranches, ok := val.([]interface{})
+	if !ok || len(rawBranches) == 0 {
+		return nil, fmt.Errorf("nested \"excludes\" of %q exclude of operation %q is not a non empty array", kind, operationName)
+	}
+
+	branches := make([]excludeBranch, len(rawBranches))
+	labels := make(map[string]bool, len(rawBranches))
+	for idx, rawBranch := range rawBranches {
+		branchConf, ok := rawBranch.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("nested exclude number %d of %q exclude of operation %q is not a map", idx, kind, operationName)
+		}
+
+		branch, err := makeExcludeBranch(operationName, branchConf, idx, resources)
+		if err != nil {
+			return nil, err
+		}
+		if labels[branch.label] {
+			return nil, fmt.Errorf("nested exclude name %q of operation %q is not unique", branch.label, operationName)
+		}
+		labels[branch.label] = true
+		branches[idx] = branch
+	}
+
+	return branches, nil
+}
+
+func makeExcludeBranch(operationName string, conf map[string]interface{}, idx int, resources map[string]types.RuntimeResource) (excludeBranch, error) {
+	exclude, err := MakeExclude(operationName, conf, resources)
+	if err != nil {
+		return excludeBranch{}, err
+	}
+
+	branch := excludeBranch{label: fmt.Sprintf("%d:%v", idx, conf["kind"]), exclude: exclude}
+	if val, ok := conf["name"]; ok {
+		name, ok := val.(string)
+		if !ok || name == "" {
+			return excludeBranch{}, fmt.Errorf("\"name\" of nested exclude of operation %q is not a string", operationName)
+		}
+		branch.label = name
+	}
+
+	return branch, nil
+}
@@ -0,0 +1,110 @@
+package exclude
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCompositeExcludes(test *testing.T) {
+	// keeps partitions before 2024-01-02 and from 2024-01-03 on respectively
+	before := map[string]interface{}{"kind": "absolute_partition", "name": "before", "from": []interface{}{"2024-01-02"}}
+	after := map[string]interface{}{"kind": "absolute_partition", "name": "after", "to": []interface{}{"2024-01-03"}}
+	// keeps only the first hour of a day
+	firstHour := map[string]interface{}{"kind": "absolute_partition", "to": []interface{}{"2024-01-01", "0"}, "toinclusive": true}
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		conf     map[string]interface{}
+		expected []string
+	}{
+		{
+			name:     "any keeps the union",
+			conf:     map[string]interface{}{"kind": "any", "excludes": []interface{}{before, after}},
+			expected: []string{"2024-01-01", "2024-01-01", "2024-01-03"},
+		},
+		{
+			name:     "all keeps the intersection",
+			conf:     map[string]interface{}{"kind": "all", "excludes": []interface{}{before, firstHour}},
+			expected: []string{"2024-01-01"},
+		},
+		{
+			name:     "not keeps the complement",
+			conf:     map[string]interface{}{"kind": "not", "exclude": before},
+			expected: []string{"2024-01-02", "2024-01-03"},
+		},
+		{
+			name: "nested composites",
+			conf: map[string]interface{}{"kind": "not", "exclude": map[string]interface{}{
+				"kind": "any", "excludes": []interface{}{before, after},
+			}},
+			expected: []string{"2024-01-02"},
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			exclude, err := MakeExclude("op", subtest.conf, nil)
+			if err != nil {
+				test.Fatalf("test %q failed for %s", subtest.name, err)
+			}
+
+			// act
+			kept, err := exclude.IgnorePartition(datedPartitions(
+				[2]string{"2024-01-01", "0"},
+				[2]string{"2024-01-01", "12"},
+				[2]string{"2024-01-02", "0"},
+				[2]string{"2024-01-03", "0"},
+			))
+
+			// assert
+			if err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			keptDates := make([]string, len(kept))
+			for idx, partition := range kept {
+				keptDates[idx] = partition.GetValues()[0]
+			}
+			sort.Strings(keptDates)
+			if strings.Join(keptDates, ",") != strings.Join(subtest.expected, ",") {
+				test.Errorf("test %q failed for %v != %v", subtest.name, keptDates, subtest.expected)
+			}
+		})
+	}
+}
+
+func TestCompositeExcludeConfig(test *testing.T) {
+	branch := map[string]interface{}{"kind": "absolute_partition", "name": "a", "from": []interface{}{"2024-01-02"}}
+
+	// arrange
+	testTabel := []struct {
+		name string
+		conf map[string]interface{}
+	}{
+		{
+			name: "missing branches",
+			conf: map[string]interface{}{"kind": "any"},
+		},
+		{
+			name: "duplicate branch names",
+			conf: map[string]interface{}{"kind": "all", "excludes": []interface{}{branch, branch}},
+		},
+		{
+			name: "invalid nested exclude",
+			conf: map[string]interface{}{"kind": "not", "exclude": map[string]interface{}{"kind": "unknown"}},
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			_, err := MakeExclude("op", subtest.conf, nil)
+
+			// assert
+			if err == nil {
+				test.Errorf("test %q failed for missing error", subtest.name)
+			}
+		})
+	}
+}
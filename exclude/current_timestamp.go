@@ -42,7 +42,7 @@ func (excludeSpec CurrentTimeExclude) IgnorePartition(
 	return keptPartitions[:partitionKeepCnt], nil
 }
 
-func MakeCurrentTimestampExclude(operationName string, conf map[string]interface{}) (types.Exclude, error) {
+func MakeCurrentTimestampExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
 	var (
 		err     error
 		exclude CurrentTimeExclude
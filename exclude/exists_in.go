@@ -0,0 +1,124 @@
+package exclude
+
+import (
+	"fmt"
+	"log"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// excludes partitions until they exist in every referenced resource (e.g. replicated to an archive and registered in trino)
+// partitions are matched by their hash just like targets are checked and completion locks are keyed
+type ExistsInExclude struct {
+	resources []types.RuntimeResource
+	// partitions with a different object count or size in a referenced resource count as missing
+	compareCount bool
+	compareSize  bool
+}
+
+func (excludeSpec ExistsInExclude) ReferencedResources() []types.RuntimeResource {
+	return excludeSpec.resources
+}
+
+func (excludeSpec ExistsInExclude) IgnorePartition(partitions types.PartitionList) (types.PartitionList, error) {
+	keptPartitions := make(types.PartitionList, 0, len(partitions))
+	for _, partition := range partitions {
+		hash := partition.GetParsedValues().ToString()
+
+		reason, err := excludeSpec.missingReason(partition)
+		if err != nil {
+			return types.PartitionList{}, err
+		}
+		if reason != "" {
+			log.Printf("exists in exclude retains partition %q: %s", hash, reason)
+			continue
+		}
+		keptPartitions = append(keptPartitions, partition)
+	}
+
+	return keptPartitions, nil
+}
+
+// why the partition is not (completely) in one of the referenced resources, empty if it is in all of them
+func (excludeSpec ExistsInExclude) missingReason(partition types.Partition) (string, error) {
+	hash := partition.GetParsedValues().ToString()
+
+	for _, resource := range excludeSpec.resources {
+		other, ok := resource.GetPartitions()[hash]
+		if !ok {
+			return fmt.Sprintf("missing in %q", resource.GetResourceName()), nil
+		}
+
+		if excludeSpec.compareCount {
+			count, otherCount, err := compareMeasure(partition, other, types.Partition.GetObjectCount)
+			if err != nil {
+				return "", fmt.Errorf("object counts of partition %q can not be compared with %q: %w", hash, resource.GetResourceName(), err)
+			}
+			if count != otherCount {
+				return fmt.Sprintf("%d instead of %d objects in %q", otherCount, count, resource.GetResourceName()), nil
+			}
+		}
+
+		if excludeSpec.compareSize {
+			size, otherSize, err := compareMeasure(partition, other, types.Partition.GetSize)
+			if err != nil {
+				return "", fmt.Errorf("sizes of partition %q can not be compared with %q: %w", hash, resource.GetResourceName(), err)
+			}
+			if size != otherSize {
+				return fmt.Sprintf("%d instead of %d bytes in %q", otherSize, size, resource.GetResourceName()), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func compareMeasure(partition types.Partition, other types.Partition, measure func(types.Partition) (int64, error)) (int64, int64, error) {
+	own, err := measure(partition)
+	if err != nil {
+		return 0, 0, err
+	}
+	others, err := measure(other)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return own, others, nil
+}
+
+func MakeExistsInExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
+	var exclude ExistsInExclude
+
+	val, ok := conf["resources"]
+	if !ok {
+		return nil, fmt.Errorf("exists in exclude of operation %q has no \"resources\"", operationName)
+	}
+	rawNames, ok := val.([]interface{})
+	if !ok || len(rawNames) == 0 {
+		return nil, fmt.Errorf("\"resources\" field in exclude of operation %q is not a non empty array", operationName)
+	}
+	for _, rawName := range rawNames {
+		name, ok := rawName.(string)
+		if !ok {
+			return nil, fmt.Errorf("\"resources\" field in exclude of operation %q contains the non string %v", operationName, rawName)
+		}
+		resource, ok := resources[name]
+		if !ok {
+			return nil, fmt.Errorf("resource %q in exclude of operation %q is no known resource", name, operationName)
+		}
+		exclude.resources = append(exclude.resources, resource)
+	}
+
+	if val, ok := conf["comparecount"]; ok {
+		if exclude.compareCount, ok = val.(bool); !ok {
+			return nil, fmt.Errorf("\"comparecount\" field in exclude of operation %q is not a boolean", operationName)
+		}
+	}
+	if val, ok := conf["comparesize"]; ok {
+		if exclude.compareSize, ok = val.(bool); !ok {
+			return nil, fmt.Errorf("\"comparesize\" field in exclude of operation %q is not a boolean", operationName)
+		}
+	}
+
+	return exclude, nil
+}
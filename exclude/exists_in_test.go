@@ -0,0 +1,75 @@
+package exclude
+
+import (
+	"testing"
+
+	"smartclip.de/cloud-cleaner/providers"
+	"smartclip.de/cloud-cleaner/resources"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+func TestExistsInExclude(test *testing.T) {
+	// the archive has the first two partitions, the second one only partially
+	archive := &resources.BaseResource{Name: "archive", Partitions: make(map[string]types.Partition)}
+	for idx, partition := range datedPartitions([2]string{"2024-01-01", "0"}, [2]string{"2024-01-02", "0"}) {
+		partition.(*providers.HivePartition).ObjectCount = 2
+		partition.(*providers.HivePartition).Size = int64(200 - idx*100)
+		archive.Partitions[partition.GetParsedValues().ToString()] = partition
+	}
+	raw := func() types.PartitionList {
+		list := datedPartitions([2]string{"2024-01-01", "0"}, [2]string{"2024-01-02", "0"}, [2]string{"2024-01-03", "0"})
+		for _, partition := range list {
+			partition.(*providers.HivePartition).ObjectCount = 2
+			partition.(*providers.HivePartition).Size = 200
+		}
+		return list
+	}
+	known := map[string]types.RuntimeResource{"archive": archive}
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		conf     map[string]interface{}
+		expected int
+	}{
+		{
+			name:     "missing partitions are retained",
+			conf:     map[string]interface{}{"resources": []interface{}{"archive"}},
+			expected: 2,
+		},
+		{
+			name:     "matching object counts",
+			conf:     map[string]interface{}{"resources": []interface{}{"archive"}, "comparecount": true},
+			expected: 2,
+		},
+		{
+			name:     "differing sizes are retained",
+			conf:     map[string]interface{}{"resources": []interface{}{"archive"}, "comparesize": true},
+			expected: 1,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			exclude, err := MakeExistsInExclude("op", subtest.conf, known)
+			if err != nil {
+				test.Fatalf("test %q failed for %s", subtest.name, err)
+			}
+
+			// act
+			kept, err := exclude.IgnorePartition(raw())
+
+			// assert
+			if err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			if len(kept) != subtest.expected {
+				test.Errorf("test %q failed for %d != %d", subtest.name, len(kept), subtest.expected)
+			}
+		})
+	}
+
+	if _, err := MakeExistsInExclude("op", map[string]interface{}{"resources": []interface{}{"unknown"}}, known); err == nil {
+		test.Errorf("test %q failed for missing error", "unknown resource")
+	}
+}
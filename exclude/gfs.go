@@ -0,0 +1,150 @@
+package exclude
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"smartclip.de/cloud-cleaner/partitions"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// calendar buckets of the gfs tiers, every bucket is named by the formatted (utc) timestamp
+var gfsPeriods = map[string]func(time.Time) string{
+	"hour":  func(ts time.Time) string { return ts.Format("2006-01-02T15") },
+	"day":   func(ts time.Time) string { return ts.Format("2006-01-02") },
+	"week":  func(ts time.Time) string { year, week := ts.ISOWeek(); return fmt.Sprintf("%d-W%02d", year, week) },
+	"month": func(ts time.Time) string { return ts.Format("2006-01") },
+	"year":  func(ts time.Time) string { return ts.Format("2006") },
+}
+
+type gfsTier struct {
+	period string
+	keep   int
+}
+
+// grandfather-father-son retention: every tier keeps the newest partition of its newest buckets
+// buckets without partitions are not counted, so gaps (e.g. a stopped pipeline) never shorten the retention
+type GFSExclude struct {
+	tiers []gfsTier
+	// date or datetime partition column used instead of the partition timestamp
+	column string
+}
+
+func (excludeSpec GFSExclude) IgnorePartition(partitions types.PartitionList) (types.PartitionList, error) {
+	sort.Sort(partitions)
+
+	timestamps := make([]time.Time, len(partitions))
+	for idx, partition := range partitions {
+		ts, err := partitionTime(partition, excludeSpec.column)
+		if err != nil {
+			return types.PartitionList{}, err
+		}
+		timestamps[idx] = ts.UTC()
+	}
+
+	// newest first, partitions of equal timestamp keep their partition order reversed
+	newestFirst := make([]int, len(partitions))
+	for idx := range newestFirst {
+		newestFirst[idx] = len(partitions) - 1 - idx
+	}
+	sort.SliceStable(newestFirst, func(i, j int) bool {
+		return timestamps[newestFirst[i]].After(timestamps[newestFirst[j]])
+	})
+
+	retained := make(map[int]bool)
+	for _, tier := range excludeSpec.tiers {
+		bucketOf := gfsPeriods[tier.period]
+		seenBuckets := make(map[string]bool)
+		for _, idx := range newestFirst {
+			if len(seenBuckets) >= tier.keep {
+				break
+			}
+			bucket := bucketOf(timestamps[idx])
+			if seenBuckets[bucket] {
+				continue
+			}
+			seenBuckets[bucket] = true
+			retained[idx] = true
+		}
+		log.Printf("gfs exclude tier %q retains %d buckets", tier.period, len(seenBuckets))
+	}
+
+	keptPartitions := make(types.PartitionList, 0, len(partitions)-len(retained))
+	for idx, partition := range partitions {
+		if !retained[idx] {
+			keptPartitions = append(keptPartitions, partition)
+		}
+	}
+	log.Printf("gfs exclude retains %d of %d partitions", len(retained), len(partitions))
+
+	return keptPartitions, nil
+}
+
+// timestamp of the partition or the value of its date(time) column
+func partitionTime(partition types.Partition, column string) (time.Time, error) {
+	if column == "" {
+		return partition.GetTimestamp()
+	}
+
+	idx, err := columnIndex(partition, column)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ts, ok := partitions.ValueTime(partition.GetParsedValues()[idx])
+	if !ok {
+		return time.Time{}, fmt.Errorf("partition column %q is neither of type date nor datetime", column)
+	}
+
+	return ts, nil
+}
+
+// position of the named column in the partition spec of the partition's resource
+func columnIndex(partition types.Partition, column string) (int, error) {
+	resource := partition.GetResource()
+	if resource == nil {
+		return 0, fmt.Errorf("partition %q has no resource to look up column %q", partition.GetParsedValues().ToString(), column)
+	}
+
+	for idx, spec := range resource.GetPartitionSpec() {
+		if spec.Name == column {
+			return idx, nil
+		}
+	}
+
+	return 0, fmt.Errorf("resource %q has no partition column %q", resource.GetResourceName(), column)
+}
+
+func MakeGFSExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
+	var exclude GFSExclude
+
+	val, ok := conf["tiers"]
+	if !ok {
+		return nil, fmt.Errorf("gfs exclude of operation %q has no \"tiers\"", operationName)
+	}
+	rawTiers, ok := val.(map[string]interface{})
+	if !ok || len(rawTiers) == 0 {
+		return nil, fmt.Errorf("\"tiers\" field in exclude of operation %q is not a non empty map", operationName)
+	}
+	for period, rawKeep := range rawTiers {
+		if _, ok := gfsPeriods[period]; !ok {
+			return nil, fmt.Errorf("gfs tier %q of operation %q is none of hour, day, week, month or year", period, operationName)
+		}
+		keep, ok := types.ConfigInt(rawKeep)
+		if !ok || keep < 1 {
+			return nil, fmt.Errorf("gfs tier %q of operation %q does not keep a positive number of buckets", period, operationName)
+		}
+		exclude.tiers = append(exclude.tiers, gfsTier{period: period, keep: keep})
+	}
+	// deterministic log output
+	sort.Slice(exclude.tiers, func(i, j int) bool { return exclude.tiers[i].period < exclude.tiers[j].period })
+
+	if val, ok := conf["column"]; ok {
+		if exclude.column, ok = val.(string); !ok || exclude.column == "" {
+			return nil, fmt.Errorf("\"column\" field in exclude of operation %q is not a string", operationName)
+		}
+	}
+
+	return exclude, nil
+}
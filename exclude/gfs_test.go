@@ -0,0 +1,96 @@
+package exclude
+
+import (
+	"testing"
+	"time"
+
+	"smartclip.de/cloud-cleaner/partitions"
+	"smartclip.de/cloud-cleaner/providers"
+	"smartclip.de/cloud-cleaner/resources"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// one partition every step starting at monday 2024-01-01, the dt column holds the date of the timestamp
+func gfsPartitions(count int, step time.Duration) types.PartitionList {
+	resource := &resources.BaseResource{PartitionSpec: []types.PartitionSpec{{Name: "dt", DataType: partitions.DateTime}}}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	list := make(types.PartitionList, count)
+	for idx := range list {
+		ts := start.Add(time.Duration(idx) * step)
+		value, _ := partitions.ParseDateTimePartition(ts.Format("2006-01-02 15:04:05"))
+		list[idx] = &providers.HivePartition{
+			BasePartition: partitions.BasePartition{
+				TypedPartitionValues: types.TypedPartitionValueList{value},
+				Resource:             resource,
+			},
+			LatestTs: ts,
+		}
+	}
+
+	return list
+}
+
+func TestGFSExclude(test *testing.T) {
+	day := 24 * time.Hour
+
+	// arrange
+	testTabel := []struct {
+		name       string
+		partitions types.PartitionList
+		exclude    GFSExclude
+		expected   int
+	}{
+		{
+			name:       "newest days are retained",
+			partitions: gfsPartitions(10, day),
+			exclude:    GFSExclude{tiers: []gfsTier{{"day", 3}}},
+			expected:   7,
+		},
+		{
+			name:       "only the newest partition of a day is retained",
+			partitions: gfsPartitions(48, time.Hour),
+			exclude:    GFSExclude{tiers: []gfsTier{{"day", 2}}},
+			expected:   46,
+		},
+		{
+			name:       "tiers are combined",
+			partitions: gfsPartitions(14, day),
+			exclude:    GFSExclude{tiers: []gfsTier{{"day", 3}, {"week", 2}}},
+			expected:   10,
+		},
+		{
+			name:       "overlapping tiers retain a partition once",
+			partitions: gfsPartitions(14, day),
+			exclude:    GFSExclude{tiers: []gfsTier{{"day", 14}, {"week", 2}}},
+			expected:   0,
+		},
+		{
+			name:       "partition column instead of timestamp",
+			partitions: gfsPartitions(90, day),
+			exclude:    GFSExclude{tiers: []gfsTier{{"month", 2}}, column: "dt"},
+			expected:   88,
+		},
+		{
+			name:       "no partitions",
+			partitions: types.PartitionList{},
+			exclude:    GFSExclude{tiers: []gfsTier{{"year", 1}}},
+			expected:   0,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			kept, err := subtest.exclude.IgnorePartition(subtest.partitions)
+
+			// assert
+			if err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			if len(kept) != subtest.expected {
+				test.Errorf("test %q failed for %d != %d", subtest.name, len(kept), subtest.expected)
+			}
+		})
+	}
+}
@@ -69,7 +69,7 @@ func (excludeSpec PartitionTimeExclude) IgnorePartition(partitions types.Partiti
 	return keptPartitions[:partitionKeepCnt], nil
 }
 
-func MakePartitionTimestampExclude(operationName string, conf map[string]interface{}) (types.Exclude, error) {
+func MakePartitionTimestampExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
 	var (
 		err     error
 		exclude PartitionTimeExclude
@@ -65,7 +65,7 @@ func (excludeSpec RelativPartitionExclude) IgnorePartition(partitions types.Part
 	return keptPartitions, nil
 }
 
-func MakeRelativPartitionExclude(operationName string, conf map[string]interface{}) (types.Exclude, error) {
+func MakeRelativPartitionExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
 	var (
 		err     error
 		exclude RelativPartitionExclude
@@ -0,0 +1,122 @@
+package exclude
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// excludes the newest partitions as long as their summed up size fits into the budget
+// partitions outside of the min/max size are never excluded and do not use up the budget
+type SizeBudgetExclude struct {
+	budget  int64
+	minSize int64
+	maxSize int64
+	// date or datetime partition column used instead of the partition timestamp
+	column string
+}
+
+func (excludeSpec SizeBudgetExclude) IgnorePartition(partitions types.PartitionList) (types.PartitionList, error) {
+	sort.Sort(partitions)
+
+	timestamps := make([]time.Time, len(partitions))
+	sizes := make([]int64, len(partitions))
+	for idx, partition := range partitions {
+		ts, err := partitionTime(partition, excludeSpec.column)
+		if err != nil {
+			return types.PartitionList{}, err
+		}
+		if sizes[idx], err = partition.GetSize(); err != nil {
+			return types.PartitionList{}, err
+		}
+		timestamps[idx] = ts
+	}
+
+	newestFirst := make([]int, len(partitions))
+	for idx := range newestFirst {
+		newestFirst[idx] = len(partitions) - 1 - idx
+	}
+	sort.SliceStable(newestFirst, func(i, j int) bool {
+		return timestamps[newestFirst[i]].After(timestamps[newestFirst[j]])
+	})
+
+	var used int64
+	retained := make(map[int]bool)
+	budgetExhausted := false
+	for _, idx := range newestFirst {
+		size := sizes[idx]
+		if size < excludeSpec.minSize || size > excludeSpec.maxSize {
+			log.Printf("size budget exclude ignores partition %q of %d bytes", partitions[idx].GetParsedValues().ToString(), size)
+			continue
+		}
+		// the budget is used up by the newest partitions only, older but smaller ones do not fill it up
+		if budgetExhausted || used+size > excludeSpec.budget {
+			budgetExhausted = true
+			continue
+		}
+		used += size
+		retained[idx] = true
+	}
+
+	keptPartitions := make(types.PartitionList, 0, len(partitions)-len(retained))
+	for idx, partition := range partitions {
+		if !retained[idx] {
+			keptPartitions = append(keptPartitions, partition)
+		}
+	}
+	log.Printf("size budget exclude retains %d partitions with %d of %d bytes", len(retained), used, excludeSpec.budget)
+
+	return keptPartitions, nil
+}
+
+func MakeSizeBudgetExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
+	var err error
+	exclude := SizeBudgetExclude{budget: math.MaxInt64, maxSize: math.MaxInt64}
+
+	_, hasBudget := conf["budget"]
+	_, hasMinSize := conf["minsize"]
+	_, hasMaxSize := conf["maxsize"]
+	if !hasBudget && !hasMinSize && !hasMaxSize {
+		return nil, fmt.Errorf("size budget exclude of operation %q has neither \"budget\", \"minsize\" nor \"maxsize\"", operationName)
+	}
+
+	if exclude.budget, err = sizeField(operationName, conf, "budget", exclude.budget); err != nil {
+		return nil, err
+	}
+	if exclude.minSize, err = sizeField(operationName, conf, "minsize", exclude.minSize); err != nil {
+		return nil, err
+	}
+	if exclude.maxSize, err = sizeField(operationName, conf, "maxsize", exclude.maxSize); err != nil {
+		return nil, err
+	}
+	if exclude.minSize > exclude.maxSize {
+		return nil, fmt.Errorf("\"minsize\" in exclude of operation %q is greater than \"maxsize\"", operationName)
+	}
+
+	if val, ok := conf["column"]; ok {
+		if exclude.column, ok = val.(string); !ok || exclude.column == "" {
+			return nil, fmt.Errorf("\"column\" field in exclude of operation %q is not a string", operationName)
+		}
+	}
+
+	return exclude, nil
+}
+
+// sizes are given in bytes
+func sizeField(operationName string, conf map[string]interface{}, field string, fallback int64) (int64, error) {
+	val, ok := conf[field]
+	if !ok {
+		return fallback, nil
+	}
+
+	size, ok := types.ConfigFloat(val)
+	if !ok || size < 0 || size >= math.MaxInt64 {
+		return 0, fmt.Errorf("%q field in exclude of operation %q is not a non negative number of bytes", field, operationName)
+	}
+
+	return int64(size), nil
+}
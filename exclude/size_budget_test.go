@@ -0,0 +1,73 @@
+package exclude
+
+import (
+	"testing"
+	"time"
+
+	"smartclip.de/cloud-cleaner/providers"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+func TestSizeBudgetExclude(test *testing.T) {
+	// sizes from the oldest to the newest partition, one day apart
+	sized := func(sizes ...int64) types.PartitionList {
+		list := gfsPartitions(len(sizes), 24*time.Hour)
+		for idx, size := range sizes {
+			list[idx].(*providers.HivePartition).Size = size
+		}
+		return list
+	}
+
+	// arrange
+	testTabel := []struct {
+		name       string
+		conf       map[string]interface{}
+		partitions types.PartitionList
+		expected   int
+	}{
+		{
+			name:       "newest partitions fill the budget",
+			conf:       map[string]interface{}{"budget": float64(100)},
+			partitions: sized(50, 50, 40, 40),
+			expected:   2,
+		},
+		{
+			name:       "older smaller partitions do not fill up the budget",
+			conf:       map[string]interface{}{"budget": float64(100)},
+			partitions: sized(10, 80, 40, 40),
+			expected:   2,
+		},
+		{
+			name:       "empty partitions are not retained",
+			conf:       map[string]interface{}{"budget": float64(100), "minsize": float64(1)},
+			partitions: sized(30, 30, 30, 0),
+			expected:   1,
+		},
+		{
+			name:       "suspiciously large partitions are not retained",
+			conf:       map[string]interface{}{"maxsize": float64(1000)},
+			partitions: sized(30, 30, 30, 5000),
+			expected:   1,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			exclude, err := MakeSizeBudgetExclude("op", subtest.conf, nil)
+			if err != nil {
+				test.Fatalf("test %q failed for %s", subtest.name, err)
+			}
+
+			// act
+			kept, err := exclude.IgnorePartition(subtest.partitions)
+
+			// assert
+			if err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			if len(kept) != subtest.expected {
+				test.Errorf("test %q failed for %d != %d", subtest.name, len(kept), subtest.expected)
+			}
+		})
+	}
+}
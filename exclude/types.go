@@ -13,19 +13,31 @@ const (
 	CurrentTimestampExcludeType                     = "current_timestamp"
 	PartitionTimestampExcludeType                   = "partition_timestamp"
 	RelativPartitionExcludeType                     = "relative_partition"
+	GFSExcludeType                                  = "gfs"
+	ColumnValueExcludeType                          = "column_value"
+	SizeBudgetExcludeType                           = "size_budget"
+	ExistsInExcludeType                             = "exists_in"
+	AllExcludeType                                  = "all"
+	AnyExcludeType                                  = "any"
+	NotExcludeType                                  = "not"
 )
 
-type ExcludeTypeFunc func(string, map[string]interface{}) (types.Exclude, error)
+type ExcludeTypeFunc func(string, map[string]interface{}, map[string]types.RuntimeResource) (types.Exclude, error)
 
 var KnownExcludes map[types.ExcludeType]ExcludeTypeFunc = map[types.ExcludeType]ExcludeTypeFunc{
 	AbsoluteTimestampExcludeType:  MakeAbsoluteTimestampExclude,
-	AbsolutePartitionExcludeType:  nil,
+	AbsolutePartitionExcludeType:  MakeAbsolutePartitionExclude,
 	CurrentTimestampExcludeType:   MakeCurrentTimestampExclude,
 	PartitionTimestampExcludeType: MakePartitionTimestampExclude,
 	RelativPartitionExcludeType:   MakeRelativPartitionExclude,
+	GFSExcludeType:                MakeGFSExclude,
+	ColumnValueExcludeType:        MakeColumnValueExclude,
+	SizeBudgetExcludeType:         MakeSizeBudgetExclude,
+	ExistsInExcludeType:           MakeExistsInExclude,
 }
 
-func MakeExclude(operationName string, conf map[string]interface{}) (types.Exclude, error) {
+// resources are passed for excludes which compare the partitions with other resources
+func MakeExclude(operationName string, conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.Exclude, error) {
 	var (
 		tmp             string
 		ok              bool
@@ -43,7 +55,7 @@ func MakeExclude(operationName string, conf map[string]interface{}) (types.Exclu
 		return nil, fmt.Errorf("\"kind\" field of operation %q is unknown exclude type %q", operationName, tmp)
 	}
 
-	exclude, err := makeExcludeFunc(operationName, conf)
+	exclude, err := makeExcludeFunc(operationName, conf, resources)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,211 @@
+package execution
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// outcome of exactly one prepared partition action
+type ActionResult struct {
+	Operation string
+	Resource  string
+	Partition string
+	Err       error
+	Duration  time.Duration
+	// retried requests of the objects of this partition
+	Retries int
+	// the action never started (e.g. the run was cancelled first)
+	Skipped bool
+}
+
+// collects action results of concurrently running workers and failures outside of partition actions
+type ExecutionReport struct {
+	mutex   sync.Mutex
+	Results []ActionResult
+	Errors  []*types.ContextError
+	// retried listing requests per provider
+	ListingRetries map[string]int64
+}
+
+// serializable form of a single failure
+type failureEntry struct {
+	Stage     string `json:"stage"`
+	Operation string `json:"operation,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Partition string `json:"partition,omitempty"`
+	Skipped   bool   `json:"skipped,omitempty"`
+	Error     string `json:"error"`
+	// object level differences found by read only operations (e.g. verify)
+	Divergence *types.DivergenceError `json:"divergence,omitempty"`
+}
+
+type failureReport struct {
+	Executed       int              `json:"executed"`
+	Failed         int              `json:"failed"`
+	Undone         int              `json:"undone"`
+	Retries        int              `json:"retries"`
+	ListingRetries map[string]int64 `json:"listingretries,omitempty"`
+	Failures       []failureEntry   `json:"failures"`
+}
+
+func (report *ExecutionReport) record(result ActionResult) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	report.Results = append(report.Results, result)
+}
+
+// errors which are not bound to a single executed action (e.g. a partition which could not be prepared)
+func (report *ExecutionReport) recordError(err error, stage string, operation string, resource string) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	for _, singleErr := range types.SplitErrors(err) {
+		report.Errors = append(report.Errors, types.WithContext(singleErr, stage, operation, resource))
+	}
+}
+
+// takes the listing retry counters of all providers, call it once listing is done (after preparation)
+func (report *ExecutionReport) RecordListingRetries(providers map[string]types.PartitionProvider) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	report.ListingRetries = make(map[string]int64, len(providers))
+	for name, provider := range providers {
+		if retries := provider.GetListingRetries(); retries > 0 {
+			report.ListingRetries[name] = retries
+		}
+	}
+}
+
+func (report *ExecutionReport) Failed() []ActionResult {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	var failed []ActionResult
+	for _, result := range report.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// partitions left undone only because their dependencies do not run in a dry run are no failure
+func (report *ExecutionReport) HasFailures() bool {
+	report.mutex.Lock()
+	errorCount := len(report.Errors)
+	report.mutex.Unlock()
+
+	for _, result := range report.Failed() {
+		var dryRun *dryRunError
+		if !result.Skipped || !errors.As(result.Err, &dryRun) {
+			return true
+		}
+	}
+
+	return errorCount > 0
+}
+
+func (report *ExecutionReport) failureReport() failureReport {
+	failed := report.Failed()
+
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+
+	summary := failureReport{Executed: len(report.Results), ListingRetries: report.ListingRetries}
+	for _, result := range report.Results {
+		summary.Retries += result.Retries
+	}
+	for _, contextErr := range report.Errors {
+		summary.Failed++
+		summary.Failures = append(summary.Failures, failureEntry{
+			Stage:     contextErr.Stage,
+			Operation: contextErr.Operation,
+			Resource:  contextErr.Resource,
+			Partition: contextErr.Partition,
+			Error:     contextErr.Err.Error(),
+		})
+	}
+	for _, result := range failed {
+		if result.Skipped {
+			summary.Executed--
+			summary.Undone++
+		} else {
+			summary.Failed++
+		}
+		entry := failureEntry{
+			Stage:     "execute",
+			Operation: result.Operation,
+			Resource:  result.Resource,
+			Partition: result.Partition,
+			Skipped:   result.Skipped,
+			Error:     result.Err.Error(),
+		}
+		errors.As(result.Err, &entry.Divergence)
+		summary.Failures = append(summary.Failures, entry)
+	}
+
+	// group the failures by operation for readability
+	sort.SliceStable(summary.Failures, func(i, j int) bool {
+		if summary.Failures[i].Operation != summary.Failures[j].Operation {
+			return summary.Failures[i].Operation < summary.Failures[j].Operation
+		}
+		return summary.Failures[i].Partition < summary.Failures[j].Partition
+	})
+
+	return summary
+}
+
+func (report *ExecutionReport) Log() {
+	summary := report.failureReport()
+
+	log.Printf(
+		"executed %d partition actions (%d failed, %d left undone, %d retried requests)",
+		summary.Executed,
+		summary.Failed,
+		summary.Undone,
+		summary.Retries,
+	)
+	for provider, retries := range summary.ListingRetries {
+		log.Printf("listing of provider %q needed %d retried requests", provider, retries)
+	}
+	for _, failure := range summary.Failures {
+		state := "failed"
+		if failure.Skipped {
+			state = "left undone"
+		}
+		log.Printf(
+			"%s of partition %q (operation %q, resource %q) %s: %s",
+			failure.Stage,
+			failure.Partition,
+			failure.Operation,
+			failure.Resource,
+			state,
+			failure.Error,
+		)
+		if failure.Divergence != nil {
+			for _, divergence := range failure.Divergence.Divergences {
+				log.Printf("  %s: %s %s", divergence.Kind, divergence.Key, divergence.Target)
+			}
+		}
+	}
+}
+
+// writes the failure report as json for further processing
+func (report *ExecutionReport) Write(path string) error {
+	content, err := json.MarshalIndent(report.failureReport(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
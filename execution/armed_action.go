@@ -1,6 +1,9 @@
 package execution
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"sync"
 	"time"
@@ -9,95 +12,224 @@ import (
 	"smartclip.de/cloud-cleaner/types"
 )
 
-func ExecuteArmedAction(conf *config.RuntimeConfig) error {
+func ExecuteArmedAction(
+	ctx context.Context,
+	conf *config.RuntimeConfig,
+	preparedOperations map[string]types.PreparedActions,
+	locks *ExecutionLocks,
+	journal *Journal,
+	report *ExecutionReport,
+) {
 	var wgOperation sync.WaitGroup
 
-	timeoutSeconds := time.Second                    // TODO: make configurable
-	globaleCompletionChan := make(chan struct{}, 10) // buffer to reduce completion wait
-	timeoutChan := make(chan time.Time)
+	providerSlots := makeProviderSlots(conf.Providers)
 
-	globaleCompletionChan <- struct{}{} // init timeout in async function
-
-	errChan := make(chan error)
 	if !conf.Armed {
-		log.Println("executing as dry run")
+		log.Println("executing as dry run (only read only operations)")
 	}
 
-	go func() {
-		for {
-			select {
-			// reset timeout in case of any partition completing
-			case <-globaleCompletionChan:
-				// convert send only channel to common channel by extracting value (<-()) and setting (()<-)
-				timeoutChan <- (<-time.After(timeoutSeconds))
-			case <-timeoutChan:
-				// this is needed since time.After() returns read only chan which can not be closed
-				close(timeoutChan)
-				return
-			}
+	for _, operation := range conf.Operations {
+		if !conf.Armed && !types.IsReadOnly(operation) {
+			// read only operations depending on it would only find the unchanged target, they are left undone
+			locks.releaseAll(operation.GetOperationName(), &dryRunError{operation: operation.GetOperationName()})
+			continue
 		}
-	}()
 
-	for _, operation := range conf.Operations {
 		wgOperation.Add(1)
 		go func(operation types.RuntimeOperationSingle) {
 			defer wgOperation.Done()
-			log.Printf("prepare operation: %q", operation.GetOperationName())
+			log.Printf("execute operation: %q", operation.GetOperationName())
+			preparedActions := preparedOperations[operation.GetOperationName()]
+
+			// without continue on error the first failing partition stops all further partitions of the operation
+			operationCtx, abortOperation := context.WithCancelCause(ctx)
+			defer abortOperation(nil)
 
-			preparedActions, err := operation.ExecuteOperation()
-			if err != nil {
-				errChan <- err
-				return
+			concurrency := operation.GetConcurrency()
+			if concurrency < 1 {
+				concurrency = conf.ActionConcurrency
+			}
+			slots := providerSlots[operation.GetOperationSource().GetProvider().GetProviderName()]
+			timeout := conf.DependencyTimeouts[operation.GetOperationName()]
+			// read only operations are repeated on resume
+			operationJournal := journal
+			if types.IsReadOnly(operation) {
+				operationJournal = nil
 			}
 
-			if conf.Armed {
-				// TODO: it might make sense to parallelize this as well
-				for _, preparedPartition := range preparedActions {
-					partition := preparedPartition.Partition
-
-					// wait for dependencies to finish first
-					waitChan := make(chan struct{})
-					go func() {
-						for _, wgDependency := range partition.GetDependencies() {
-							// will unblock when dependency partitions get finished
-							wgDependency.Wait()
+			actionChan := make(chan types.PreparedPartitionAction)
+			var wgWorker sync.WaitGroup
+			for i := 0; i < concurrency; i++ {
+				wgWorker.Add(1)
+				go func() {
+					defer wgWorker.Done()
+					for preparedAction := range actionChan {
+						result := executePartitionAction(operationCtx, operation, preparedAction, locks, slots, timeout.For(preparedAction.Partition), operationJournal)
+						report.record(result)
+
+						if result.Err != nil && !result.Skipped && !operation.GetContinueOnError() {
+							abortOperation(fmt.Errorf("operation aborted after partition %q failed", result.Partition))
 						}
-						close(waitChan)
-					}()
-
-					// either unblock by cleared dependencies or error on timeout
-					select {
-					case <-waitChan:
-						// TODO: this in dangerous
-						//if err := preparedPartition.Action(); err != nil {
-						//	errChan <- err
-						//	return
-						//}
-
-						partition.CloseCompleteChan()
-						globaleCompletionChan <- struct{}{}
-					case <-timeoutChan:
-						log.Printf(
-							"partition %q of operation %q timed out (could not unblock)",
-							partition.GetParsedValues().ToString(),
-							operation.GetOperationName(),
-						)
 					}
+				}()
+			}
+
+			// after cancellation no further partition gets started, in-flight ones abort in between objects
+			for idx, preparedAction := range preparedActions {
+				select {
+				case actionChan <- preparedAction:
+					continue
+				case <-operationCtx.Done():
+				}
 
+				for _, undoneAction := range preparedActions[idx:] {
+					result := skippedAction(operation, undoneAction, context.Cause(operationCtx))
+					report.record(result)
+					locks.release(result.Operation, result.Partition, result.Err)
 				}
+				break
+			}
+			close(actionChan)
+			wgWorker.Wait()
+
+			// kept partitions without an action failed to prepare, dependent operations must not proceed on them
+			keptPartitions, _ := operation.GetKeptPartitions()
+			for _, partition := range keptPartitions {
+				locks.release(operation.GetOperationName(), partition.GetParsedValues().ToString(), fmt.Errorf("partition was not prepared"))
 			}
+			// excluded partitions never got an action but dependent operations wait for them as well
+			locks.releaseAll(operation.GetOperationName(), nil)
 		}(operation)
 	}
 
-	go func() {
-		wgOperation.Wait()
-		close(errChan)
-	}()
+	wgOperation.Wait()
+}
+
+// stands in for the outcome of operations which do not run in a dry run
+type dryRunError struct {
+	operation string
+}
+
+func (err *dryRunError) Error() string {
+	return fmt.Sprintf("operation %q is not executed in a dry run", err.operation)
+}
+
+// waits for all dependencies of the partition, runs its action and only afterwards releases its completion lock
+func executePartitionAction(
+	ctx context.Context,
+	operation types.RuntimeOperationSingle,
+	preparedAction types.PreparedPartitionAction,
+	locks *ExecutionLocks,
+	slots chan struct{},
+	timeout time.Duration,
+	journal *Journal,
+) ActionResult {
+	partition := preparedAction.Partition
+	result := ActionResult{
+		Operation: operation.GetOperationName(),
+		Resource:  operation.GetOperationSource().GetResourceName(),
+		Partition: partition.GetParsedValues().ToString(),
+	}
+	// dependent operations learn about the outcome of this partition
+	defer func() { locks.release(result.Operation, result.Partition, result.Err) }()
+
+	// wait for dependencies to finish first
+	if err := locks.wait(ctx, result.Operation, result.Partition, timeout); err != nil {
+		result.Err = err
+		// a dependency exceeding its timeout fails the partition, otherwise it is just left undone
+		var timeoutErr *dependencyTimeoutError
+		if errors.As(err, &timeoutErr) {
+			log.Printf("partition %q of operation %q failed: %s", result.Partition, result.Operation, err)
+		} else {
+			log.Printf("partition %q of operation %q skipped: %s", result.Partition, result.Operation, err)
+			result.Skipped = true
+		}
+		return result
+	}
+
+	// dependencies are awaited before taking a provider slot so waiting partitions can not starve others
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-ctx.Done():
+			result.Err = context.Cause(ctx)
+			result.Skipped = true
+			return result
+		}
+	}
+
+	// operations override single fields of the retry policy of their source provider
+	policy := operation.GetRetryPolicy().Inherit(operation.GetOperationSource().GetProvider().GetRetryPolicy())
+
+	locks.start(result.Operation, result.Partition)
+	start := time.Now()
+	result.Retries, result.Err = runPartitionAction(ctx, result.Operation, result.Partition, preparedAction, policy, journal)
+	result.Duration = time.Since(start)
+
+	if result.Err != nil {
+		log.Printf("partition %q of operation %q failed: %s", result.Partition, result.Operation, result.Err)
+	} else {
+		log.Printf("partition %q of operation %q finished in %s", result.Partition, result.Operation, result.Duration)
+	}
+
+	return result
+}
+
+// runs the object actions one after another (stopping in between once cancelled) or the partition level action
+// every single action is retried on its own, the journal only records its final outcome
+func runPartitionAction(
+	ctx context.Context,
+	operationName string,
+	partitionHash string,
+	preparedAction types.PreparedPartitionAction,
+	policy types.RetryPolicy,
+	journal *Journal,
+) (retries int, err error) {
+	withRetry := func(action func() error) func() error {
+		return func() error {
+			actionRetries, err := policy.Do(ctx, action)
+			retries += actionRetries
+			return err
+		}
+	}
+
+	if preparedAction.Action != nil {
+		err = journal.run(operationName, partitionHash, "", withRetry(preparedAction.Action))
+		return
+	}
+
+	for idx, object := range preparedAction.Objects {
+		if ctx.Err() != nil {
+			return retries, fmt.Errorf("aborted after %d of %d objects: %w", idx, len(preparedAction.Objects), context.Cause(ctx))
+		}
+		if err = journal.run(operationName, partitionHash, object.Key, withRetry(object.Action)); err != nil {
+			return
+		}
+	}
 
-	err := <-errChan
-	if err != nil {
-		return err
+	return
+}
+
+// record partitions which never got started
+func skippedAction(operation types.RuntimeOperationSingle, preparedAction types.PreparedPartitionAction, err error) ActionResult {
+	return ActionResult{
+		Operation: operation.GetOperationName(),
+		Resource:  operation.GetOperationSource().GetResourceName(),
+		Partition: preparedAction.Partition.GetParsedValues().ToString(),
+		Err:       err,
+		Skipped:   true,
+	}
+}
+
+// one semaphore per provider shared by all operations working on it
+func makeProviderSlots(providers map[string]types.PartitionProvider) map[string]chan struct{} {
+	slots := make(map[string]chan struct{}, len(providers))
+	for name, provider := range providers {
+		if concurrency := provider.GetActionConcurrency(); concurrency > 0 {
+			slots[name] = make(chan struct{}, concurrency)
+		}
 	}
 
-	return nil
+	return slots
 }
@@ -0,0 +1,385 @@
+package execution
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"smartclip.de/cloud-cleaner/config"
+	"smartclip.de/cloud-cleaner/operations"
+	"smartclip.de/cloud-cleaner/partitions"
+	pp "smartclip.de/cloud-cleaner/providers"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// runtime config of the s3 resources "raw" and "archive" which both hold the given dt partitions
+// operations are parsed like the setup does, only their direct dependencies are set up
+func makeTestConfig(rawOperations string, partitionValues ...string) (*config.RuntimeConfig, error) {
+	provider := &pp.S3HiveProvider{}
+	conf := &config.RuntimeConfig{
+		Providers:          map[string]types.PartitionProvider{"s3": provider},
+		Resources:          make(map[string]types.RuntimeResource),
+		Operations:         make(map[string]types.RuntimeOperationSingle),
+		Dependencies:       make(map[string][]string),
+		DependencyTimeouts: make(map[string]types.DependencyTimeout),
+	}
+	conf.Armed = true
+	conf.ActionConcurrency = 1
+
+	for _, name := range []string{"raw", "archive"} {
+		resource, err := provider.MakeRuntimResource(map[string]interface{}{
+			"name":          name,
+			"prefix":        "s3://bucket/" + name + "/",
+			"partitionspec": []interface{}{map[string]interface{}{"name": "dt", "datatype": "string"}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		resource.SetProvider(provider)
+
+		for _, value := range partitionValues {
+			partition := &pp.HivePartition{
+				ObjectCount:   1,
+				BasePartition: partitions.BasePartition{Resource: resource, PartitionValues: []string{value}},
+			}
+			if partition.TypedPartitionValues, err = partitions.ParsePartitionString(resource.GetPartitionSpec(), partition.PartitionValues); err != nil {
+				return nil, err
+			}
+			resource.IncorporatePartition(partition)
+		}
+		conf.Resources[name] = resource
+	}
+
+	var operationConfs []map[string]interface{}
+	if err := json.Unmarshal([]byte(rawOperations), &operationConfs); err != nil {
+		return nil, err
+	}
+	for _, operationConf := range operationConfs {
+		action, _ := operationConf["action"].(string)
+		operation, err := operations.KnownActions[types.Action(action)](operationConf, conf.Resources)
+		if err != nil {
+			return nil, err
+		}
+
+		name := operation.GetOperationName()
+		conf.Operations[name] = operation
+		conf.Dependencies[name] = operation.GetDependencies()
+		conf.DependencyTimeouts[name] = operation.GetDependencyTimeout().Inherit(types.DependencyTimeout{Base: time.Minute})
+	}
+
+	return conf, nil
+}
+
+// one partition level action per source partition of every operation
+// the action of "<operation> <partition>" runs the given behaviour, all others just succeed
+func makeTestActions(conf *config.RuntimeConfig, behaviour map[string]func() error) map[string]types.PreparedActions {
+	preparedOperations := make(map[string]types.PreparedActions, len(conf.Operations))
+	for name, operation := range conf.Operations {
+		for _, partition := range operation.GetOperationSource().GetPartitions() {
+			action, ok := behaviour[name+" "+partition.GetParsedValues().ToString()]
+			if !ok {
+				action = func() error { return nil }
+			}
+			preparedOperations[name] = append(preparedOperations[name], types.PreparedPartitionAction{Partition: partition, Action: action})
+		}
+	}
+
+	return preparedOperations
+}
+
+// "<operation> <partition>" -> done, failed or undone
+func resultStates(report *ExecutionReport) map[string]string {
+	states := make(map[string]string, len(report.Results))
+	for _, result := range report.Results {
+		state := "done"
+		if result.Skipped {
+			state = "undone"
+		} else if result.Err != nil {
+			state = "failed"
+		}
+		states[result.Operation+" "+result.Partition] = state
+	}
+
+	return states
+}
+
+// action taking the given time
+func sleeping(duration time.Duration) func() error {
+	return func() error {
+		time.Sleep(duration)
+		return nil
+	}
+}
+
+func TestExecuteArmedAction(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name       string
+		operations string
+		behaviour  map[string]func() error
+		expected   map[string]string
+	}{
+		{
+			name: "every partition runs",
+			operations: `[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "concurrency": 2}
+			]`,
+			expected: map[string]string{"copy 2023-01-01": "done", "copy 2023-01-02": "done", "copy 2023-01-03": "done"},
+		},
+		{
+			name: "continue on error runs the remaining partitions",
+			operations: `[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "continueonerror": true}
+			]`,
+			behaviour: map[string]func() error{
+				"copy 2023-01-02": func() error { return fmt.Errorf("copy failed") },
+			},
+			expected: map[string]string{"copy 2023-01-01": "done", "copy 2023-01-02": "failed", "copy 2023-01-03": "done"},
+		},
+		{
+			name: "independent operations do not affect each other",
+			operations: `[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "continueonerror": true},
+				{"name": "backup", "action": "copy", "source": "archive", "target": "raw", "continueonerror": true}
+			]`,
+			behaviour: map[string]func() error{
+				"copy 2023-01-01": func() error { return fmt.Errorf("copy failed") },
+			},
+			expected: map[string]string{
+				"copy 2023-01-01":   "failed",
+				"copy 2023-01-02":   "done",
+				"copy 2023-01-03":   "done",
+				"backup 2023-01-01": "done",
+				"backup 2023-01-02": "done",
+				"backup 2023-01-03": "done",
+			},
+		},
+		{
+			name: "failed dependency leaves the partition undone",
+			operations: `[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "continueonerror": true},
+				{"name": "backup", "action": "copy", "source": "archive", "target": "raw", "dependson": ["copy"]}
+			]`,
+			behaviour: map[string]func() error{
+				"copy 2023-01-02": func() error { return fmt.Errorf("copy failed") },
+			},
+			expected: map[string]string{
+				"copy 2023-01-01":   "done",
+				"copy 2023-01-02":   "failed",
+				"copy 2023-01-03":   "done",
+				"backup 2023-01-01": "done",
+				"backup 2023-01-02": "undone",
+				"backup 2023-01-03": "done",
+			},
+		},
+		{
+			name: "undone partitions block their dependents as well",
+			operations: `[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "continueonerror": true},
+				{"name": "backup", "action": "copy", "source": "archive", "target": "raw", "dependson": ["copy"]},
+				{"name": "delete", "action": "delete", "source": "raw", "dependson": ["backup"]}
+			]`,
+			behaviour: map[string]func() error{
+				"copy 2023-01-02": func() error { return fmt.Errorf("copy failed") },
+			},
+			expected: map[string]string{
+				"copy 2023-01-01":   "done",
+				"copy 2023-01-02":   "failed",
+				"copy 2023-01-03":   "done",
+				"backup 2023-01-01": "done",
+				"backup 2023-01-02": "undone",
+				"backup 2023-01-03": "done",
+				"delete 2023-01-01": "done",
+				"delete 2023-01-02": "undone",
+				"delete 2023-01-03": "done",
+			},
+		},
+		{
+			name: "dependency exceeding the timeout fails the partition",
+			operations: `[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive", "concurrency": 3},
+				{"name": "backup", "action": "copy", "source": "archive", "target": "raw", "dependson": ["copy"], "concurrency": 3, "continueonerror": true, "dependencytimeout": "50ms"}
+			]`,
+			behaviour: map[string]func() error{
+				"copy 2023-01-02": sleeping(200 * time.Millisecond),
+			},
+			expected: map[string]string{
+				"copy 2023-01-01":   "done",
+				"copy 2023-01-02":   "done",
+				"copy 2023-01-03":   "done",
+				"backup 2023-01-01": "done",
+				"backup 2023-01-02": "failed",
+				"backup 2023-01-03": "done",
+			},
+		},
+		{
+			name: "timeout starts with the dependency partition",
+			operations: `[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive"},
+				{"name": "backup", "action": "copy", "source": "archive", "target": "raw", "dependson": ["copy"], "concurrency": 3, "dependencytimeout": "150ms"}
+			]`,
+			behaviour: map[string]func() error{
+				"copy 2023-01-01": sleeping(100 * time.Millisecond),
+				"copy 2023-01-02": sleeping(100 * time.Millisecond),
+				"copy 2023-01-03": sleeping(100 * time.Millisecond),
+			},
+			expected: map[string]string{
+				"copy 2023-01-01":   "done",
+				"copy 2023-01-02":   "done",
+				"copy 2023-01-03":   "done",
+				"backup 2023-01-01": "done",
+				"backup 2023-01-02": "done",
+				"backup 2023-01-03": "done",
+			},
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// arrange
+			conf, err := makeTestConfig(subtest.operations, "2023-01-01", "2023-01-02", "2023-01-03")
+			if err != nil {
+				test.Fatalf("test %q failed to set up: %s", subtest.name, err)
+			}
+			report := &ExecutionReport{}
+
+			// act
+			ExecuteArmedAction(context.Background(), conf, makeTestActions(conf, subtest.behaviour), CreateExecutionLocks(conf), nil, report)
+
+			// assert
+			if states := resultStates(report); !reflect.DeepEqual(states, subtest.expected) {
+				test.Errorf("test %q failed for %v != %v", subtest.name, states, subtest.expected)
+			}
+		})
+	}
+}
+
+func TestExecuteArmedActionConcurrency(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name        string
+		concurrency string
+		expected    int
+	}{
+		{
+			name:        "global action concurrency",
+			concurrency: "",
+			expected:    1,
+		},
+		{
+			name:        "operation concurrency",
+			concurrency: `, "concurrency": 3`,
+			expected:    3,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// arrange
+			conf, err := makeTestConfig(
+				fmt.Sprintf(`[{"name": "copy", "action": "copy", "source": "raw", "target": "archive"%s}]`, subtest.concurrency),
+				"2023-01-01", "2023-01-02", "2023-01-03", "2023-01-04", "2023-01-05", "2023-01-06",
+			)
+			if err != nil {
+				test.Fatalf("test %q failed to set up: %s", subtest.name, err)
+			}
+
+			var (
+				mutex            sync.Mutex
+				running, maximum int
+			)
+			behaviour := make(map[string]func() error)
+			for partitionHash := range conf.Resources["raw"].GetPartitions() {
+				behaviour["copy "+partitionHash] = func() error {
+					mutex.Lock()
+					running++
+					if running > maximum {
+						maximum = running
+					}
+					mutex.Unlock()
+
+					time.Sleep(20 * time.Millisecond)
+
+					mutex.Lock()
+					running--
+					mutex.Unlock()
+					return nil
+				}
+			}
+
+			// act
+			ExecuteArmedAction(context.Background(), conf, makeTestActions(conf, behaviour), CreateExecutionLocks(conf), nil, &ExecutionReport{})
+
+			// assert
+			if maximum != subtest.expected {
+				test.Errorf("test %q failed for %d != %d concurrent partitions", subtest.name, maximum, subtest.expected)
+			}
+		})
+	}
+}
+
+func TestExecuteArmedActionDryRun(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name       string
+		operations string
+		behaviour  map[string]func() error
+		expected   map[string]string
+		failures   bool
+	}{
+		{
+			name: "verify after a copy is left undone",
+			operations: `[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive"},
+				{"name": "verify", "action": "verify", "source": "raw", "target": "archive", "dependson": ["copy"]}
+			]`,
+			expected: map[string]string{"verify 2023-01-01": "undone", "verify 2023-01-02": "undone"},
+		},
+		{
+			name: "independent verify runs",
+			operations: `[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive"},
+				{"name": "verify", "action": "verify", "source": "archive", "target": "raw"}
+			]`,
+			expected: map[string]string{"verify 2023-01-01": "done", "verify 2023-01-02": "done"},
+		},
+		{
+			name: "diverging verify fails the dry run",
+			operations: `[
+				{"name": "verify", "action": "verify", "source": "archive", "target": "raw", "continueonerror": true}
+			]`,
+			behaviour: map[string]func() error{
+				"verify 2023-01-02": func() error { return fmt.Errorf("objects differ") },
+			},
+			expected: map[string]string{"verify 2023-01-01": "done", "verify 2023-01-02": "failed"},
+			failures: true,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// arrange
+			conf, err := makeTestConfig(subtest.operations, "2023-01-01", "2023-01-02")
+			if err != nil {
+				test.Fatalf("test %q failed to set up: %s", subtest.name, err)
+			}
+			conf.Armed = false
+			report := &ExecutionReport{}
+
+			// act
+			ExecuteArmedAction(context.Background(), conf, makeTestActions(conf, subtest.behaviour), CreateExecutionLocks(conf), nil, report)
+
+			// assert
+			if states := resultStates(report); !reflect.DeepEqual(states, subtest.expected) {
+				test.Errorf("test %q failed for %v != %v", subtest.name, states, subtest.expected)
+			}
+			if failures := report.HasFailures(); failures != subtest.failures {
+				test.Errorf("test %q failed for failures %t != %t", subtest.name, failures, subtest.failures)
+			}
+		})
+	}
+}
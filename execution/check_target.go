@@ -8,7 +8,7 @@ import (
 	"smartclip.de/cloud-cleaner/types"
 )
 
-func CheckOperationTargets(conf *config.RuntimeConfig) error {
+func CheckOperationTargets(conf *config.RuntimeConfig, journal *Journal) error {
 	var wg sync.WaitGroup
 	errChan := make(chan error)
 
@@ -17,18 +17,34 @@ func CheckOperationTargets(conf *config.RuntimeConfig) error {
 		go func(operation types.RuntimeOperation) {
 			defer wg.Done()
 
+			// e.g. sync and verify expect the partitions in the target
+			if comparing, ok := operation.(types.TargetComparingOperation); ok && comparing.ComparesTarget() {
+				return
+			}
+
 			if op, ok := operation.(types.RuntimeOperationDouble); ok {
 				resource := op.GetOperationTarget()
 				targetPartitions := resource.GetPartitions()
 
 				sourcePartitions, err := op.GetKeptPartitions()
 				if err != nil {
-					errChan <- err
+					errChan <- types.WithContext(err, "check target", op.GetOperationName(), op.GetOperationSource().GetResourceName())
+					return
 				}
 
 				for _, sourcePartition := range sourcePartitions {
+					// partially copied partitions of an interrupted run are expected in the target
+					if journal.Touched(op.GetOperationName(), sourcePartition.GetParsedValues().ToString()) {
+						continue
+					}
 					if _, ok := targetPartitions[sourcePartition.GetParsedValues().ToString()]; ok {
-						errChan <- fmt.Errorf("operation %q has source partition in target already", op.GetOperationName())
+						errChan <- &types.ContextError{
+							Stage:     "check target",
+							Operation: op.GetOperationName(),
+							Resource:  resource.GetResourceName(),
+							Partition: sourcePartition.GetParsedValues().ToString(),
+							Err:       fmt.Errorf("source partition exists in target already"),
+						}
 					}
 				}
 			}
@@ -40,10 +56,5 @@ func CheckOperationTargets(conf *config.RuntimeConfig) error {
 		close(errChan)
 	}()
 
-	err := <-errChan
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return types.CollectErrors(errChan)
 }
@@ -1,59 +1,150 @@
 package execution
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"smartclip.de/cloud-cleaner/config"
 )
 
-func CreateExecutionLocks(conf *config.RuntimeConfig) error {
-	var wg sync.WaitGroup
+// released once its operation is done with the partition, err holds the outcome (nil on success)
+type completionLock struct {
+	operation string
+	once      sync.Once
+	done      chan struct{}
+	err       error
+	// closed once the action of the partition starts, startTime may be read afterwards
+	startOnce sync.Once
+	started   chan struct{}
+	startTime time.Time
+}
+
+// the dependency did not finish the partition in time after starting it
+type dependencyTimeoutError struct {
+	operation string
+	timeout   time.Duration
+}
+
+func (err *dependencyTimeoutError) Error() string {
+	return fmt.Sprintf("dependency %q did not complete within %s after starting the partition (see \"dependencytimeout\")", err.operation, err.timeout)
+}
+
+func (lock *completionLock) start() {
+	lock.startOnce.Do(func() {
+		lock.startTime = time.Now()
+		close(lock.started)
+	})
+}
+
+// only the first outcome counts, err may be read by everyone once done is closed
+func (lock *completionLock) release(err error) {
+	lock.once.Do(func() {
+		lock.err = err
+		close(lock.done)
+	})
+}
+
+// the timeout only runs once the partition started, queueing for a worker or provider slot does not count
+func (lock *completionLock) wait(ctx context.Context, timeout time.Duration) error {
+	select {
+	case <-lock.started:
+	case <-lock.done:
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
 
-	for _, operation := range conf.Operations {
-		log.Printf("setup execution locks for operation %q", operation.GetOperationName())
-		currentResource := operation.GetOperationSource()
+	select {
+	case <-lock.done:
+	default:
+		deadline := time.NewTimer(time.Until(lock.startTime.Add(timeout)))
+		defer deadline.Stop()
 
-		// register every operation in this partition so it waits longer until sending completation
-		for _, partition := range currentResource.GetPartitions() {
-			partition.WaitForCompletion()
+		select {
+		case <-lock.done:
+		case <-deadline.C:
+			return &dependencyTimeoutError{operation: lock.operation, timeout: timeout}
+		case <-ctx.Done():
+			return context.Cause(ctx)
 		}
+	}
 
-		for _, dependency := range operation.GetDependencies() {
-			otherOperation, ok := conf.Operations[dependency]
-			if !ok {
-				return fmt.Errorf(
-					"oppeartion %q assumed dependency %q which does not exist",
-					operation.GetOperationName(),
-					dependency,
-				)
-			}
-			otherResource := otherOperation.GetOperationSource()
-
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-
-				for partitionHash, currentPartition := range currentResource.GetPartitions() {
-					if otherPartition, ok := otherResource.GetPartitions()[partitionHash]; ok {
-						log.Printf(
-							"blocking partition %q for %q by %q",
-							partitionHash,
-							currentResource.GetResourceName(),
-							otherResource.GetResourceName(),
-						)
-
-						wgOther := otherPartition.RegisterCompletionLock()
-						log.Printf("wait sync: %+v - %p", currentPartition, wgOther)
-						currentPartition.AddDependencies(wgOther)
-					}
+	if lock.err != nil {
+		return fmt.Errorf("blocked by dependency %q: %w", lock.operation, lock.err)
+	}
+
+	return nil
+}
+
+// every operation holds one completion lock per source partition and partitions wait for
+// the locks of the same partition in all operations they (transitively) depend on
+type ExecutionLocks struct {
+	// operation -> partition hash
+	completion   map[string]map[string]*completionLock
+	dependencies map[string]map[string][]*completionLock
+}
+
+func CreateExecutionLocks(conf *config.RuntimeConfig) *ExecutionLocks {
+	locks := &ExecutionLocks{
+		completion:   make(map[string]map[string]*completionLock, len(conf.Operations)),
+		dependencies: make(map[string]map[string][]*completionLock, len(conf.Operations)),
+	}
+
+	for name, operation := range conf.Operations {
+		log.Printf("setup execution locks for operation %q", name)
+
+		partitions := operation.GetOperationSource().GetPartitions()
+		locks.completion[name] = make(map[string]*completionLock, len(partitions))
+		for partitionHash := range partitions {
+			locks.completion[name][partitionHash] = &completionLock{operation: name, done: make(chan struct{}), started: make(chan struct{})}
+		}
+	}
+
+	for name, operation := range conf.Operations {
+		locks.dependencies[name] = make(map[string][]*completionLock)
+		for _, dependency := range conf.Dependencies[name] {
+			for partitionHash := range operation.GetOperationSource().GetPartitions() {
+				if lock, ok := locks.completion[dependency][partitionHash]; ok {
+					log.Printf("blocking partition %q of %q by %q", partitionHash, name, dependency)
+					locks.dependencies[name][partitionHash] = append(locks.dependencies[name][partitionHash], lock)
 				}
-			}()
+			}
 		}
 	}
 
-	wg.Wait()
+	return locks
+}
+
+// blocks until all dependencies of the partition are done and errors if any of them failed or timed out
+func (locks *ExecutionLocks) wait(ctx context.Context, operation string, partitionHash string, timeout time.Duration) error {
+	for _, lock := range locks.dependencies[operation][partitionHash] {
+		if err := lock.wait(ctx, timeout); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
+
+// starts the timeout of dependent partitions
+func (locks *ExecutionLocks) start(operation string, partitionHash string) {
+	if lock, ok := locks.completion[operation][partitionHash]; ok {
+		lock.start()
+	}
+}
+
+// safe to call more than once per partition, only the first outcome is kept
+func (locks *ExecutionLocks) release(operation string, partitionHash string, err error) {
+	if lock, ok := locks.completion[operation][partitionHash]; ok {
+		lock.release(err)
+	}
+}
+
+// releases every remaining lock of the operation, e.g. those of excluded partitions
+func (locks *ExecutionLocks) releaseAll(operation string, err error) {
+	for _, lock := range locks.completion[operation] {
+		lock.release(err)
+	}
+}
@@ -0,0 +1,117 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestExecutionLocks(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name      string
+		release   func(locks *ExecutionLocks)
+		operation string
+		err       string
+	}{
+		{
+			name:      "no dependencies",
+			release:   func(locks *ExecutionLocks) {},
+			operation: "copy",
+		},
+		{
+			name: "succeeded dependency",
+			release: func(locks *ExecutionLocks) {
+				locks.release("copy", "2023-01-01", nil)
+			},
+			operation: "backup",
+		},
+		{
+			name: "failed dependency",
+			release: func(locks *ExecutionLocks) {
+				locks.release("copy", "2023-01-01", fmt.Errorf("copy failed"))
+			},
+			operation: "backup",
+			err:       `blocked by dependency "copy": copy failed`,
+		},
+		{
+			name: "only the first outcome counts",
+			release: func(locks *ExecutionLocks) {
+				locks.release("copy", "2023-01-01", fmt.Errorf("copy failed"))
+				locks.release("copy", "2023-01-01", nil)
+			},
+			operation: "backup",
+			err:       `blocked by dependency "copy": copy failed`,
+		},
+		{
+			name: "release all remaining partitions",
+			release: func(locks *ExecutionLocks) {
+				locks.release("copy", "2023-01-02", fmt.Errorf("copy failed"))
+				locks.releaseAll("copy", nil)
+			},
+			operation: "backup",
+		},
+		{
+			name: "failure of any dependency",
+			release: func(locks *ExecutionLocks) {
+				locks.release("copy", "2023-01-01", fmt.Errorf("copy failed"))
+				locks.release("backup", "2023-01-01", nil)
+			},
+			operation: "delete",
+			err:       `blocked by dependency "copy": copy failed`,
+		},
+		{
+			name: "started dependency exceeding the timeout",
+			release: func(locks *ExecutionLocks) {
+				locks.start("copy", "2023-01-01")
+			},
+			operation: "backup",
+			err:       `dependency "copy" did not complete within 10ms after starting the partition (see "dependencytimeout")`,
+		},
+		{
+			name: "dependency finishing within the timeout",
+			release: func(locks *ExecutionLocks) {
+				locks.start("copy", "2023-01-01")
+				go func() {
+					time.Sleep(5 * time.Millisecond)
+					locks.release("copy", "2023-01-01", nil)
+				}()
+			},
+			operation: "backup",
+		},
+		{
+			name:      "queued dependency does not time out",
+			release:   func(locks *ExecutionLocks) {},
+			operation: "backup",
+			err:       "context deadline exceeded",
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// arrange
+			conf, err := makeTestConfig(`[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive"},
+				{"name": "backup", "action": "copy", "source": "archive", "target": "raw", "dependson": ["copy"]},
+				{"name": "delete", "action": "delete", "source": "raw", "dependson": ["copy", "backup"]}
+			]`, "2023-01-01", "2023-01-02")
+			if err != nil {
+				test.Fatalf("test %q failed to set up: %s", subtest.name, err)
+			}
+			locks := CreateExecutionLocks(conf)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			// act
+			subtest.release(locks)
+			err = locks.wait(ctx, subtest.operation, "2023-01-01", 10*time.Millisecond)
+
+			// assert
+			if (err == nil) != (subtest.err == "") || (err != nil && err.Error() != subtest.err) {
+				test.Errorf("test %q failed with unexpected error %v != %q", subtest.name, err, subtest.err)
+			}
+		})
+	}
+}
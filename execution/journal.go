@@ -0,0 +1,186 @@
+package execution
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	journalStarted  = "started"
+	journalDone     = "done"
+	journalFailed   = "failed"
+	journalFileMode = 0644
+)
+
+// one line of the append only journal file
+type journalEntry struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Partition string    `json:"partition"`
+	Object    string    `json:"object,omitempty"`
+	State     string    `json:"state"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// records every object level action before and after execution so an interrupted run can be resumed
+// a nil journal is valid and just executes the actions
+type Journal struct {
+	mutex     sync.Mutex
+	file      *os.File
+	encoder   *json.Encoder
+	completed map[string]struct{}
+	touched   map[string]struct{}
+}
+
+// opens the journal for appending, on resume the already completed actions are loaded first
+func OpenJournal(path string, resume bool) (*Journal, error) {
+	journal := &Journal{
+		completed: make(map[string]struct{}),
+		touched:   make(map[string]struct{}),
+	}
+
+	if resume {
+		if err := journal.load(path); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, journalFileMode)
+	if err != nil {
+		return nil, err
+	}
+	journal.file = file
+	journal.encoder = json.NewEncoder(file)
+
+	return journal, nil
+}
+
+func (journal *Journal) load(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("journal %q does not exist yet, nothing to resume", path)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	inFlight := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// the last line may be cut off by the crash we are recovering from
+			log.Printf("skipping unreadable journal line %d: %s", lineNumber, err)
+			continue
+		}
+
+		key := journalKey(entry.Operation, entry.Partition, entry.Object)
+		journal.touched[journalKey(entry.Operation, entry.Partition, "")] = struct{}{}
+		switch entry.State {
+		case journalStarted, journalFailed:
+			inFlight[key] = struct{}{}
+		case journalDone:
+			journal.completed[key] = struct{}{}
+			delete(inFlight, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	log.Printf("resuming journal %q: %d completed and %d unfinished actions", path, len(journal.completed), len(inFlight))
+	return nil
+}
+
+func (journal *Journal) Close() error {
+	if journal == nil {
+		return nil
+	}
+
+	return journal.file.Close()
+}
+
+// true if an earlier run already completed this action
+func (journal *Journal) Completed(operation string, partition string, object string) bool {
+	if journal == nil {
+		return false
+	}
+
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	_, ok := journal.completed[journalKey(operation, partition, object)]
+	return ok
+}
+
+// true if an earlier run already started to work on the partition
+func (journal *Journal) Touched(operation string, partition string) bool {
+	if journal == nil {
+		return false
+	}
+
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	_, ok := journal.touched[journalKey(operation, partition, "")]
+	return ok
+}
+
+// skips completed actions and otherwise surrounds the action by journal entries
+func (journal *Journal) run(operation string, partition string, object string, action func() error) error {
+	if journal == nil {
+		return action()
+	}
+
+	if journal.Completed(operation, partition, object) {
+		log.Printf("skipping %q of operation %q (completed according to journal)", object, operation)
+		return nil
+	}
+
+	if err := journal.write(journalEntry{Operation: operation, Partition: partition, Object: object, State: journalStarted}); err != nil {
+		return fmt.Errorf("could not write journal before action: %w", err)
+	}
+
+	actionErr := action()
+
+	entry := journalEntry{Operation: operation, Partition: partition, Object: object, State: journalDone}
+	if actionErr != nil {
+		entry.State = journalFailed
+		entry.Error = actionErr.Error()
+	}
+	if err := journal.write(entry); err != nil && actionErr == nil {
+		return fmt.Errorf("could not write journal after action: %w", err)
+	}
+
+	return actionErr
+}
+
+func (journal *Journal) write(entry journalEntry) error {
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	entry.Time = time.Now().UTC()
+	// every entry is one write syscall in append mode so a crashing process leaves at most a cut off last line
+	if err := journal.encoder.Encode(entry); err != nil {
+		return err
+	}
+
+	if entry.State == journalDone {
+		journal.completed[journalKey(entry.Operation, entry.Partition, entry.Object)] = struct{}{}
+	}
+
+	return nil
+}
+
+func journalKey(operation string, partition string, object string) string {
+	return operation + "\n" + partition + "\n" + object
+}
@@ -0,0 +1,260 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writes the entries (and optionally a cut off last line) to a journal file in a temporary directory
+func writeTestJournal(t *testing.T, cutOff string, entries ...journalEntry) (string, error) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	var content strings.Builder
+	encoder := json.NewEncoder(&content)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return "", err
+		}
+	}
+	content.WriteString(cutOff)
+
+	return path, os.WriteFile(path, []byte(content.String()), journalFileMode)
+}
+
+// journal resuming the given entries, closed once the test is done
+func resumeTestJournal(t *testing.T, entries ...journalEntry) (*Journal, error) {
+	path, err := writeTestJournal(t, "", entries...)
+	if err != nil {
+		return nil, err
+	}
+
+	journal, err := OpenJournal(path, true)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() { journal.Close() })
+
+	return journal, nil
+}
+
+func TestOpenJournal(test *testing.T) {
+	type expected struct {
+		completed []string
+		touched   []string
+	}
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		entries  []journalEntry
+		cutOff   string
+		resume   bool
+		expected expected
+	}{
+		{
+			name: "completed objects",
+			entries: []journalEntry{
+				{Operation: "copy", Partition: "2023-01-01", Object: "a", State: journalStarted},
+				{Operation: "copy", Partition: "2023-01-01", Object: "a", State: journalDone},
+				{Operation: "copy", Partition: "2023-01-01", Object: "b", State: journalStarted},
+			},
+			resume:   true,
+			expected: expected{completed: []string{"copy 2023-01-01 a"}, touched: []string{"copy 2023-01-01"}},
+		},
+		{
+			name: "failed objects are repeated",
+			entries: []journalEntry{
+				{Operation: "copy", Partition: "2023-01-01", Object: "a", State: journalStarted},
+				{Operation: "copy", Partition: "2023-01-01", Object: "a", State: journalFailed, Error: "copy failed"},
+			},
+			resume:   true,
+			expected: expected{touched: []string{"copy 2023-01-01"}},
+		},
+		{
+			name: "completed partition level action",
+			entries: []journalEntry{
+				{Operation: "move", Partition: "2023-01-01", State: journalStarted},
+				{Operation: "move", Partition: "2023-01-01", State: journalDone},
+			},
+			resume:   true,
+			expected: expected{completed: []string{"move 2023-01-01 "}, touched: []string{"move 2023-01-01"}},
+		},
+		{
+			name: "cut off last line",
+			entries: []journalEntry{
+				{Operation: "copy", Partition: "2023-01-01", Object: "a", State: journalDone},
+			},
+			cutOff:   `{"operation":"copy","partition":"2023-01-02","obj`,
+			resume:   true,
+			expected: expected{completed: []string{"copy 2023-01-01 a"}, touched: []string{"copy 2023-01-01"}},
+		},
+		{
+			name: "without resume",
+			entries: []journalEntry{
+				{Operation: "copy", Partition: "2023-01-01", Object: "a", State: journalDone},
+			},
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// arrange
+			path, err := writeTestJournal(t, subtest.cutOff, subtest.entries...)
+			if err != nil {
+				test.Fatalf("test %q could not write journal: %s", subtest.name, err)
+			}
+
+			// act
+			journal, err := OpenJournal(path, subtest.resume)
+
+			// assert
+			if err != nil {
+				test.Fatalf("%q failed with unexpected error %q", subtest.name, err)
+			}
+			defer journal.Close()
+
+			var result expected
+			for _, entry := range subtest.entries {
+				if journal.Completed(entry.Operation, entry.Partition, entry.Object) {
+					result.completed = appendMissing(result.completed, entry.Operation+" "+entry.Partition+" "+entry.Object)
+				}
+				if journal.Touched(entry.Operation, entry.Partition) {
+					result.touched = appendMissing(result.touched, entry.Operation+" "+entry.Partition)
+				}
+			}
+			if journal.Touched("copy", "2023-01-02") {
+				test.Errorf("test %q failed, cut off line was not skipped", subtest.name)
+			}
+			if !reflect.DeepEqual(result, subtest.expected) {
+				test.Errorf("test %q failed for %v != %v", subtest.name, result, subtest.expected)
+			}
+		})
+	}
+}
+
+func appendMissing(list []string, element string) []string {
+	for _, existing := range list {
+		if existing == element {
+			return list
+		}
+	}
+
+	return append(list, element)
+}
+
+func TestJournalRun(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name      string
+		entries   []journalEntry
+		actionErr error
+		executed  bool
+		states    []string
+		completed bool
+	}{
+		{
+			name:      "successful action",
+			executed:  true,
+			states:    []string{journalStarted, journalDone},
+			completed: true,
+		},
+		{
+			name:      "failed action",
+			actionErr: fmt.Errorf("copy failed"),
+			executed:  true,
+			states:    []string{journalStarted, journalFailed},
+		},
+		{
+			name: "completed by an earlier run",
+			entries: []journalEntry{
+				{Operation: "copy", Partition: "2023-01-01", Object: "a", State: journalDone},
+			},
+			states:    []string{journalDone},
+			completed: true,
+		},
+		{
+			name: "interrupted by an earlier run",
+			entries: []journalEntry{
+				{Operation: "copy", Partition: "2023-01-01", Object: "a", State: journalStarted},
+			},
+			executed:  true,
+			states:    []string{journalStarted, journalStarted, journalDone},
+			completed: true,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// arrange
+			path, err := writeTestJournal(t, "", subtest.entries...)
+			if err != nil {
+				test.Fatalf("test %q could not write journal: %s", subtest.name, err)
+			}
+			journal, err := OpenJournal(path, true)
+			if err != nil {
+				test.Fatalf("test %q could not open journal: %s", subtest.name, err)
+			}
+
+			// act
+			executed := false
+			err = journal.run("copy", "2023-01-01", "a", func() error {
+				executed = true
+				return subtest.actionErr
+			})
+			completed := journal.Completed("copy", "2023-01-01", "a")
+			journal.Close()
+
+			// assert
+			if err != subtest.actionErr {
+				test.Errorf("test %q failed with unexpected error %v != %v", subtest.name, err, subtest.actionErr)
+			}
+			if completed != subtest.completed {
+				test.Errorf("test %q failed for completed %t != %t", subtest.name, completed, subtest.completed)
+			}
+			if executed != subtest.executed {
+				test.Errorf("test %q failed for executed %t != %t", subtest.name, executed, subtest.executed)
+			}
+
+			content, _ := os.ReadFile(path)
+			var states []string
+			for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+				var entry journalEntry
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					test.Fatalf("test %q wrote unreadable journal line %q: %s", subtest.name, line, err)
+				}
+				states = append(states, entry.State)
+			}
+			if !reflect.DeepEqual(states, subtest.states) {
+				test.Errorf("test %q failed for journal states %v != %v", subtest.name, states, subtest.states)
+			}
+		})
+	}
+}
+
+func TestNilJournal(test *testing.T) {
+	// arrange
+	var journal *Journal
+
+	// act
+	executed := false
+	err := journal.run("copy", "2023-01-01", "a", func() error {
+		executed = true
+		return nil
+	})
+
+	// assert
+	if err != nil || !executed {
+		test.Errorf("nil journal did not just execute the action: %t %v", executed, err)
+	}
+	if journal.Completed("copy", "2023-01-01", "a") || journal.Touched("copy", "2023-01-01") {
+		test.Errorf("nil journal reported earlier progress")
+	}
+	if err := journal.Close(); err != nil {
+		test.Errorf("nil journal failed to close: %s", err)
+	}
+}
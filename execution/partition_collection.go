@@ -1,6 +1,7 @@
 package execution
 
 import (
+	"context"
 	"log"
 	"sync"
 
@@ -8,20 +9,23 @@ import (
 	"smartclip.de/cloud-cleaner/types"
 )
 
-func StartProviders(conf *config.RuntimeConfig) error {
-	var wgProvider sync.WaitGroup
+func StartProviders(ctx context.Context, conf *config.RuntimeConfig) error {
+	var wgWorker sync.WaitGroup
 
 	providerChan := make(chan types.PartitionProvider)
 	errChan := make(chan error)
 
 	for i := 0; i < conf.ProviderConcurrency; i++ {
 		log.Printf("provider worker start (id: %d)", i)
-		go collectPartitions(providerChan, conf.Sources, errChan, &wgProvider)
+		wgWorker.Add(1)
+		go func() {
+			defer wgWorker.Done()
+			collectPartitions(ctx, providerChan, conf.Sources, errChan)
+		}()
 	}
 
 	go func() {
 		for providerName, provider := range conf.Providers {
-			wgProvider.Add(1)
 			log.Printf("provider %q queued", providerName)
 			providerChan <- provider
 		}
@@ -30,26 +34,27 @@ func StartProviders(conf *config.RuntimeConfig) error {
 		close(providerChan)
 	}()
 
-	err := <-errChan
-	if err != nil {
-		return err
-	}
+	// errors are only closed once every worker is done so none of them is lost
+	go func() {
+		wgWorker.Wait()
+		close(errChan)
+	}()
 
-	return nil
+	return types.CollectErrors(errChan)
 }
 
 func collectPartitions(
+	ctx context.Context,
 	providerChan <-chan types.PartitionProvider,
 	sources map[string]types.RuntimeResource,
-	errChan chan error,
-	wgProvider *sync.WaitGroup,
+	errChan chan<- error,
 ) {
-	var wgResource sync.WaitGroup
-
 	for provider := range providerChan {
+		var wgResource sync.WaitGroup
+
 		for i := 0; i < provider.GetResourceConcurrency(); i++ {
 			log.Printf("resource worker start (id: %d) for provider %q", i, provider.GetProviderName())
-			go provider.CollectPartitions(errChan, &wgResource)
+			go provider.CollectPartitions(ctx, errChan, &wgResource)
 		}
 
 		for _, resource := range provider.GetRelatedResources() {
@@ -63,10 +68,8 @@ func collectPartitions(
 			log.Printf("partition collection for resource %q started", resource.GetResourceName())
 		}
 		wgResource.Wait()
+		// lets the resource workers of this provider return
+		close(provider.ResourceInputChan())
 		log.Printf("partition collection for provider %q finished", provider.GetProviderName())
-		wgProvider.Done()
 	}
-
-	wgProvider.Wait()
-	close(errChan)
 }
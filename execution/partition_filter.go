@@ -24,7 +24,8 @@ func FilterKeptPartitions(conf *config.RuntimeConfig) error {
 
 			partitionList, err := operation.GetKeptPartitions()
 			if err != nil {
-				errChan <- err
+				errChan <- types.WithContext(err, "filter", operation.GetOperationName(), operation.GetOperationSource().GetResourceName())
+				return
 			}
 
 			log.Printf("partition count after filter for operation %q: %d", operation.GetOperationName(), len(partitionList))
@@ -36,10 +37,5 @@ func FilterKeptPartitions(conf *config.RuntimeConfig) error {
 		close(errChan)
 	}()
 
-	err := <-errChan
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return types.CollectErrors(errChan)
 }
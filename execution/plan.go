@@ -0,0 +1,248 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"smartclip.de/cloud-cleaner/config"
+	"smartclip.de/cloud-cleaner/partitions"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// bump whenever the plan file layout changes in an incompatible way
+const planVersion = 1
+
+type Plan struct {
+	Version int             `json:"version"`
+	Created time.Time       `json:"created"`
+	Actions []PlannedAction `json:"actions"`
+}
+
+// serializable counterpart of types.PreparedPartitionAction
+type PlannedAction struct {
+	Operation string                 `json:"operation"`
+	Resource  string                 `json:"resource"`
+	Partition []string               `json:"partition"`
+	Objects   []types.PreparedObject `json:"objects"`
+}
+
+func MakePlan(conf *config.RuntimeConfig, preparedOperations map[string]types.PreparedActions) Plan {
+	plan := Plan{Version: planVersion, Created: time.Now().UTC()}
+
+	for operationName, preparedActions := range preparedOperations {
+		resourceName := conf.Operations[operationName].GetOperationSource().GetResourceName()
+		for _, preparedAction := range preparedActions {
+			plan.Actions = append(plan.Actions, PlannedAction{
+				Operation: operationName,
+				Resource:  resourceName,
+				Partition: preparedAction.Partition.GetValues(),
+				Objects:   preparedAction.Objects,
+			})
+		}
+	}
+
+	// stable ordering keeps plan files diffable
+	sort.Slice(plan.Actions, func(i, j int) bool {
+		if plan.Actions[i].Operation != plan.Actions[j].Operation {
+			return plan.Actions[i].Operation < plan.Actions[j].Operation
+		}
+		return strings.Join(plan.Actions[i].Partition, "/") < strings.Join(plan.Actions[j].Partition, "/")
+	})
+
+	return plan
+}
+
+func (plan Plan) Write(path string) error {
+	content, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
+
+func ReadPlan(path string) (Plan, error) {
+	var plan Plan
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Plan{}, err
+	}
+	if err = json.Unmarshal(content, &plan); err != nil {
+		return Plan{}, fmt.Errorf("plan file %q is not valid: %w", path, err)
+	}
+	if plan.Version != planVersion {
+		return Plan{}, fmt.Errorf("plan file %q has version %d but only version %d is supported", path, plan.Version, planVersion)
+	}
+
+	return plan, nil
+}
+
+// replaces the kept partitions of every operation by the partitions of the plan
+func RestrictToPlan(conf *config.RuntimeConfig, plan Plan, journal *Journal) error {
+	plannedPartitions := make(map[string]types.PartitionList, len(conf.Operations))
+
+	for _, plannedAction := range plan.Actions {
+		operation, ok := conf.Operations[plannedAction.Operation]
+		if !ok {
+			return fmt.Errorf("planned operation %q is not configured", plannedAction.Operation)
+		}
+
+		source := operation.GetOperationSource()
+		if source.GetResourceName() != plannedAction.Resource {
+			return fmt.Errorf(
+				"planned operation %q works on resource %q but is configured for %q",
+				plannedAction.Operation,
+				plannedAction.Resource,
+				source.GetResourceName(),
+			)
+		}
+
+		parsedValues, err := partitions.ParsePartitionString(source.GetPartitionSpec(), plannedAction.Partition)
+		if err != nil {
+			return err
+		}
+		partition, ok := source.GetPartitions()[parsedValues.ToString()]
+		if !ok && journal.Touched(plannedAction.Operation, parsedValues.ToString()) {
+			log.Printf("planned partition %v of operation %q was finished by an earlier run", plannedAction.Partition, plannedAction.Operation)
+			continue
+		}
+		if !ok {
+			return fmt.Errorf(
+				"planned partition %v of operation %q does not exist anymore",
+				plannedAction.Partition,
+				plannedAction.Operation,
+			)
+		}
+
+		plannedPartitions[plannedAction.Operation] = append(plannedPartitions[plannedAction.Operation], partition)
+	}
+
+	for operationName, operation := range conf.Operations {
+		partitionList, ok := plannedPartitions[operationName]
+		if !ok {
+			partitionList = types.PartitionList{}
+		}
+		operation.SetKeptPartitions(partitionList)
+	}
+
+	return nil
+}
+
+// compares freshly prepared actions against the plan and fails on any object which changed in between
+func (plan Plan) CheckDrift(
+	conf *config.RuntimeConfig,
+	preparedOperations map[string]types.PreparedActions,
+	journal *Journal,
+) error {
+	var drift []string
+
+	prepared := make(map[string]types.PreparedPartitionAction)
+	for operationName, preparedActions := range preparedOperations {
+		for _, preparedAction := range preparedActions {
+			prepared[operationName+"\t"+preparedAction.Partition.GetParsedValues().ToString()] = preparedAction
+		}
+	}
+
+	for _, plannedAction := range plan.Actions {
+		source := conf.Operations[plannedAction.Operation].GetOperationSource()
+		parsedValues, err := partitions.ParsePartitionString(source.GetPartitionSpec(), plannedAction.Partition)
+		if err != nil {
+			return err
+		}
+
+		partitionHash := parsedValues.ToString()
+		preparedAction, ok := prepared[plannedAction.Operation+"\t"+partitionHash]
+		if !ok && journal.Touched(plannedAction.Operation, partitionHash) {
+			// e.g. all objects got deleted by an interrupted apply of this plan
+			continue
+		}
+		if !ok {
+			drift = append(drift, fmt.Sprintf("%s %v: partition was not prepared again", plannedAction.Operation, plannedAction.Partition))
+			continue
+		}
+
+		// objects handled by an interrupted apply of this plan are expected to differ
+		plannedObjects := pendingObjects(journal, plannedAction.Operation, partitionHash, plannedAction.Objects)
+		currentObjects := pendingObjects(journal, plannedAction.Operation, partitionHash, preparedAction.Objects)
+		if preparedAction.Action != nil && journal.Touched(plannedAction.Operation, partitionHash) {
+			// partition level actions (e.g. move) are journaled as a whole, an interrupted one removed an unknown part
+			plannedObjects = remainingObjects(plannedObjects, currentObjects)
+		}
+
+		for _, msg := range objectDrift(plannedObjects, currentObjects) {
+			drift = append(drift, fmt.Sprintf("%s %v: %s", plannedAction.Operation, plannedAction.Partition, msg))
+		}
+	}
+
+	if len(drift) > 0 {
+		return fmt.Errorf("refusing to apply plan, found %d differences since planning:\n%s", len(drift), strings.Join(drift, "\n"))
+	}
+
+	return nil
+}
+
+// drops the objects an interrupted apply of this plan already completed
+func pendingObjects(journal *Journal, operation string, partitionHash string, objects []types.PreparedObject) []types.PreparedObject {
+	var pending []types.PreparedObject
+	for _, object := range objects {
+		if !journal.Completed(operation, partitionHash, object.Key) {
+			pending = append(pending, object)
+		}
+	}
+
+	return pending
+}
+
+// planned objects which still exist
+func remainingObjects(planned []types.PreparedObject, current []types.PreparedObject) []types.PreparedObject {
+	currentKeys := make(map[string]struct{}, len(current))
+	for _, object := range current {
+		currentKeys[object.Key] = struct{}{}
+	}
+
+	var remaining []types.PreparedObject
+	for _, object := range planned {
+		if _, ok := currentKeys[object.Key]; ok {
+			remaining = append(remaining, object)
+		}
+	}
+
+	return remaining
+}
+
+func objectDrift(planned []types.PreparedObject, current []types.PreparedObject) []string {
+	var drift []string
+
+	currentObjects := make(map[string]types.PreparedObject, len(current))
+	for _, object := range current {
+		currentObjects[object.Key] = object
+	}
+
+	for _, plannedObject := range planned {
+		currentObject, ok := currentObjects[plannedObject.Key]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("%q disappeared", plannedObject.Key))
+			continue
+		}
+		delete(currentObjects, plannedObject.Key)
+
+		if currentObject.Size != plannedObject.Size {
+			drift = append(drift, fmt.Sprintf("%q changed size %d -> %d", plannedObject.Key, plannedObject.Size, currentObject.Size))
+		}
+		if currentObject.ETag != plannedObject.ETag {
+			drift = append(drift, fmt.Sprintf("%q changed etag %s -> %s", plannedObject.Key, plannedObject.ETag, currentObject.ETag))
+		}
+	}
+
+	for key := range currentObjects {
+		drift = append(drift, fmt.Sprintf("%q appeared", key))
+	}
+
+	return drift
+}
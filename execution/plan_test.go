@@ -0,0 +1,318 @@
+package execution
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// objects of one byte with the etag "etag"
+func testObjects(keys ...string) []types.PreparedObject {
+	objects := make([]types.PreparedObject, len(keys))
+	for idx, key := range keys {
+		objects[idx] = types.PreparedObject{Key: key, Size: 1, ETag: "etag"}
+	}
+
+	return objects
+}
+
+func TestObjectDrift(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name     string
+		planned  []types.PreparedObject
+		current  []types.PreparedObject
+		expected []string
+	}{
+		{
+			name:    "unchanged",
+			planned: testObjects("s3://bucket/raw/dt=1/a", "s3://bucket/raw/dt=1/b"),
+			current: testObjects("s3://bucket/raw/dt=1/b", "s3://bucket/raw/dt=1/a"),
+		},
+		{
+			name:     "disappeared",
+			planned:  testObjects("s3://bucket/raw/dt=1/a", "s3://bucket/raw/dt=1/b"),
+			current:  testObjects("s3://bucket/raw/dt=1/a"),
+			expected: []string{`"s3://bucket/raw/dt=1/b" disappeared`},
+		},
+		{
+			name:     "appeared",
+			planned:  testObjects("s3://bucket/raw/dt=1/a"),
+			current:  testObjects("s3://bucket/raw/dt=1/a", "s3://bucket/raw/dt=1/b"),
+			expected: []string{`"s3://bucket/raw/dt=1/b" appeared`},
+		},
+		{
+			name:    "changed size and etag",
+			planned: testObjects("s3://bucket/raw/dt=1/a"),
+			current: []types.PreparedObject{{Key: "s3://bucket/raw/dt=1/a", Size: 2, ETag: "other"}},
+			expected: []string{
+				`"s3://bucket/raw/dt=1/a" changed size 1 -> 2`,
+				`"s3://bucket/raw/dt=1/a" changed etag etag -> other`,
+			},
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			drift := objectDrift(subtest.planned, subtest.current)
+
+			// assert
+			if !reflect.DeepEqual(drift, subtest.expected) {
+				test.Errorf("test %q failed for %q != %q", subtest.name, drift, subtest.expected)
+			}
+		})
+	}
+}
+
+func TestReadPlan(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name    string
+		version int
+		err     bool
+	}{
+		{
+			name:    "current version",
+			version: planVersion,
+		},
+		{
+			name:    "other version",
+			version: planVersion + 1,
+			err:     true,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// arrange
+			path := filepath.Join(t.TempDir(), "plan.json")
+			plan := Plan{Version: subtest.version, Actions: []PlannedAction{
+				{Operation: "copy", Resource: "raw", Partition: []string{"2023-01-01"}, Objects: testObjects("s3://bucket/raw/dt=2023-01-01/a")},
+			}}
+			if err := plan.Write(path); err != nil {
+				test.Fatalf("test %q could not write plan: %s", subtest.name, err)
+			}
+
+			// act
+			readPlan, err := ReadPlan(path)
+
+			// assert
+			if (err == nil) == subtest.err {
+				test.Errorf("%q failed with unexpected error %q", subtest.name, err)
+			}
+			if err == nil && !reflect.DeepEqual(readPlan, plan) {
+				test.Errorf("test %q failed for %v != %v", subtest.name, readPlan, plan)
+			}
+		})
+	}
+}
+
+func TestRestrictToPlan(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name     string
+		actions  []PlannedAction
+		journal  []journalEntry
+		expected map[string][]string
+		err      string
+	}{
+		{
+			name: "only planned partitions are kept",
+			actions: []PlannedAction{
+				{Operation: "copy", Resource: "raw", Partition: []string{"2023-01-02"}},
+				{Operation: "copy", Resource: "raw", Partition: []string{"2023-01-03"}},
+			},
+			expected: map[string][]string{"copy": {"2023-01-02", "2023-01-03"}, "delete": {}},
+		},
+		{
+			name: "unknown operation",
+			actions: []PlannedAction{
+				{Operation: "move", Resource: "raw", Partition: []string{"2023-01-02"}},
+			},
+			err: `planned operation "move" is not configured`,
+		},
+		{
+			name: "other resource",
+			actions: []PlannedAction{
+				{Operation: "copy", Resource: "archive", Partition: []string{"2023-01-02"}},
+			},
+			err: `planned operation "copy" works on resource "archive" but is configured for "raw"`,
+		},
+		{
+			name: "vanished partition",
+			actions: []PlannedAction{
+				{Operation: "delete", Resource: "raw", Partition: []string{"2023-01-04"}},
+			},
+			err: `planned partition [2023-01-04] of operation "delete" does not exist anymore`,
+		},
+		{
+			name: "vanished partition finished by an earlier run",
+			actions: []PlannedAction{
+				{Operation: "delete", Resource: "raw", Partition: []string{"2023-01-03"}},
+				{Operation: "delete", Resource: "raw", Partition: []string{"2023-01-04"}},
+			},
+			journal: []journalEntry{
+				{Operation: "delete", Partition: "2023-01-04", Object: "s3://bucket/raw/dt=2023-01-04/a", State: journalDone},
+			},
+			expected: map[string][]string{"copy": {}, "delete": {"2023-01-03"}},
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// arrange
+			conf, err := makeTestConfig(`[
+				{"name": "copy", "action": "copy", "source": "raw", "target": "archive"},
+				{"name": "delete", "action": "delete", "source": "raw", "dependson": ["copy"]}
+			]`, "2023-01-01", "2023-01-02", "2023-01-03")
+			if err != nil {
+				test.Fatalf("test %q failed to set up: %s", subtest.name, err)
+			}
+			journal, err := resumeTestJournal(t, subtest.journal...)
+			if err != nil {
+				test.Fatalf("test %q could not resume journal: %s", subtest.name, err)
+			}
+
+			// act
+			err = RestrictToPlan(conf, Plan{Version: planVersion, Actions: subtest.actions}, journal)
+
+			// assert
+			if (err == nil) != (subtest.err == "") || (err != nil && err.Error() != subtest.err) {
+				test.Errorf("test %q failed with unexpected error %v != %q", subtest.name, err, subtest.err)
+			}
+			if err != nil {
+				return
+			}
+
+			kept := make(map[string][]string, len(conf.Operations))
+			for name, operation := range conf.Operations {
+				partitions, _ := operation.GetKeptPartitions()
+				kept[name] = []string{}
+				for _, partition := range partitions {
+					kept[name] = append(kept[name], partition.GetValues()...)
+				}
+				sort.Strings(kept[name])
+			}
+			if !reflect.DeepEqual(kept, subtest.expected) {
+				test.Errorf("test %q failed for %v != %v", subtest.name, kept, subtest.expected)
+			}
+		})
+	}
+}
+
+func TestCheckDrift(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name    string
+		planned []string
+		current []string
+		// the current action is partition level like the one of move
+		partitionAction bool
+		journal         []journalEntry
+		err             string
+	}{
+		{
+			name:    "unchanged",
+			planned: []string{"s3://bucket/raw/dt=2023-01-01/a", "s3://bucket/raw/dt=2023-01-01/b"},
+			current: []string{"s3://bucket/raw/dt=2023-01-01/a", "s3://bucket/raw/dt=2023-01-01/b"},
+		},
+		{
+			name:    "changed objects",
+			planned: []string{"s3://bucket/raw/dt=2023-01-01/a", "s3://bucket/raw/dt=2023-01-01/b"},
+			current: []string{"s3://bucket/raw/dt=2023-01-01/a", "s3://bucket/raw/dt=2023-01-01/c"},
+			err: "refusing to apply plan, found 2 differences since planning:\n" +
+				`copy [2023-01-01]: "s3://bucket/raw/dt=2023-01-01/b" disappeared` + "\n" +
+				`copy [2023-01-01]: "s3://bucket/raw/dt=2023-01-01/c" appeared`,
+		},
+		{
+			name:    "partition not prepared again",
+			planned: []string{"s3://bucket/raw/dt=2023-01-01/a"},
+			err: "refusing to apply plan, found 1 differences since planning:\n" +
+				"copy [2023-01-01]: partition was not prepared again",
+		},
+		{
+			name:    "partition finished by an earlier run",
+			planned: []string{"s3://bucket/raw/dt=2023-01-01/a"},
+			journal: []journalEntry{
+				{Operation: "copy", Partition: "2023-01-01", Object: "s3://bucket/raw/dt=2023-01-01/a", State: journalDone},
+			},
+		},
+		{
+			name:    "resumed copy",
+			planned: []string{"s3://bucket/raw/dt=2023-01-01/a", "s3://bucket/raw/dt=2023-01-01/b"},
+			current: []string{"s3://bucket/raw/dt=2023-01-01/a", "s3://bucket/raw/dt=2023-01-01/b"},
+			journal: []journalEntry{
+				{Operation: "copy", Partition: "2023-01-01", Object: "s3://bucket/raw/dt=2023-01-01/a", State: journalStarted},
+				{Operation: "copy", Partition: "2023-01-01", Object: "s3://bucket/raw/dt=2023-01-01/a", State: journalDone},
+				{Operation: "copy", Partition: "2023-01-01", Object: "s3://bucket/raw/dt=2023-01-01/b", State: journalStarted},
+			},
+		},
+		{
+			name:    "resumed copy with a vanished pending object",
+			planned: []string{"s3://bucket/raw/dt=2023-01-01/a", "s3://bucket/raw/dt=2023-01-01/b"},
+			current: []string{"s3://bucket/raw/dt=2023-01-01/a"},
+			journal: []journalEntry{
+				{Operation: "copy", Partition: "2023-01-01", Object: "s3://bucket/raw/dt=2023-01-01/a", State: journalDone},
+			},
+			err: "refusing to apply plan, found 1 differences since planning:\n" +
+				`copy [2023-01-01]: "s3://bucket/raw/dt=2023-01-01/b" disappeared`,
+		},
+		{
+			name:            "resumed partition level action",
+			planned:         []string{"s3://bucket/raw/dt=2023-01-01/a", "s3://bucket/raw/dt=2023-01-01/b"},
+			current:         []string{"s3://bucket/raw/dt=2023-01-01/b"},
+			partitionAction: true,
+			journal: []journalEntry{
+				{Operation: "copy", Partition: "2023-01-01", State: journalStarted},
+			},
+		},
+		{
+			name:            "partition level action which did not start yet",
+			planned:         []string{"s3://bucket/raw/dt=2023-01-01/a", "s3://bucket/raw/dt=2023-01-01/b"},
+			current:         []string{"s3://bucket/raw/dt=2023-01-01/b"},
+			partitionAction: true,
+			err: "refusing to apply plan, found 1 differences since planning:\n" +
+				`copy [2023-01-01]: "s3://bucket/raw/dt=2023-01-01/a" disappeared`,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// arrange
+			conf, err := makeTestConfig(`[{"name": "copy", "action": "copy", "source": "raw", "target": "archive"}]`, "2023-01-01")
+			if err != nil {
+				test.Fatalf("test %q failed to set up: %s", subtest.name, err)
+			}
+			plan := Plan{Version: planVersion, Actions: []PlannedAction{
+				{Operation: "copy", Resource: "raw", Partition: []string{"2023-01-01"}, Objects: testObjects(subtest.planned...)},
+			}}
+			preparedOperations := map[string]types.PreparedActions{}
+			if subtest.current != nil {
+				preparedAction := types.PreparedPartitionAction{
+					Partition: conf.Resources["raw"].GetPartitions()["2023-01-01"],
+					Objects:   testObjects(subtest.current...),
+				}
+				if subtest.partitionAction {
+					preparedAction.Action = func() error { return nil }
+				}
+				preparedOperations["copy"] = types.PreparedActions{preparedAction}
+			}
+			journal, err := resumeTestJournal(t, subtest.journal...)
+			if err != nil {
+				test.Fatalf("test %q could not resume journal: %s", subtest.name, err)
+			}
+
+			// act
+			err = plan.CheckDrift(conf, preparedOperations, journal)
+
+			// assert
+			if (err == nil) != (subtest.err == "") || (err != nil && err.Error() != subtest.err) {
+				test.Errorf("test %q failed with unexpected error %v != %q", subtest.name, err, subtest.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package execution
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"smartclip.de/cloud-cleaner/config"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// prepares the partition actions of all operations without executing any of them
+// failures of operations continuing on error go to the report, all others are returned
+func PrepareActions(
+	ctx context.Context,
+	conf *config.RuntimeConfig,
+	report *ExecutionReport,
+) (map[string]types.PreparedActions, error) {
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+	)
+
+	preparedActions := make(map[string]types.PreparedActions, len(conf.Operations))
+	errChan := make(chan error)
+
+	for _, operation := range conf.Operations {
+		wg.Add(1)
+		go func(operation types.RuntimeOperationSingle) {
+			defer wg.Done()
+
+			operationName := operation.GetOperationName()
+			resourceName := operation.GetOperationSource().GetResourceName()
+			log.Printf("prepare operation: %q", operationName)
+
+			actions, err := operation.ExecuteOperation(ctx)
+			if err != nil && operation.GetContinueOnError() {
+				log.Printf("operation %q continues without its failed partitions", operationName)
+				report.recordError(err, "prepare", operationName, resourceName)
+			} else if err != nil {
+				for _, singleErr := range types.SplitErrors(err) {
+					errChan <- types.WithContext(singleErr, "prepare", operationName, resourceName)
+				}
+				return
+			}
+
+			mutex.Lock()
+			preparedActions[operationName] = actions
+			mutex.Unlock()
+		}(operation)
+	}
+
+	go func() {
+		wg.Wait()
+		close(errChan)
+	}()
+
+	if err := types.CollectErrors(errChan); err != nil {
+		return nil, err
+	}
+
+	return preparedActions, nil
+}
@@ -0,0 +1,36 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"smartclip.de/cloud-cleaner/config"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// stops the run before anything is prepared if an operation would touch more than its guards allow
+func CheckSafetyGuards(conf *config.RuntimeConfig) error {
+	var errs []error
+
+	for _, operation := range conf.Operations {
+		err := operation.CheckSafetyGuards()
+		if err == nil {
+			continue
+		}
+
+		if conf.OverrideGuards {
+			log.Printf("ignoring safety guards of operation %q (--override-guards): %s", operation.GetOperationName(), err)
+			continue
+		}
+		for _, singleErr := range types.SplitErrors(err) {
+			errs = append(errs, types.WithContext(singleErr, "guard", operation.GetOperationName(), operation.GetOperationSource().GetResourceName()))
+		}
+	}
+
+	if len(errs) > 0 {
+		errs = append(errs, fmt.Errorf("check the excludes of the operations above or rerun with --override-guards if this is intended"))
+	}
+
+	return errors.Join(errs...)
+}
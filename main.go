@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"smartclip.de/cloud-cleaner/config"
 	"smartclip.de/cloud-cleaner/execution"
@@ -16,36 +20,104 @@ type nested struct {
 }
 
 func main() {
+	// first signal cancels the run gracefully, a second one kills the process as usual
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		log.Printf("received shutdown signal, finishing in-flight partitions")
+		stop()
+	}()
+
 	log.Printf("runtime config setup:")
-	conf, err := config.Setup()
+	conf, err := config.Setup(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	var journal *execution.Journal
+	if conf.JournalFile != "" {
+		if journal, err = execution.OpenJournal(conf.JournalFile, conf.Resume); err != nil {
+			log.Fatal(err)
+		}
+		defer journal.Close()
+	}
+
+	var plan execution.Plan
+	if conf.Mode == config.ApplyMode {
+		if plan, err = execution.ReadPlan(conf.PlanFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	log.Printf("partition collection:")
-	if err := execution.StartProviders(&conf); err != nil {
+	if err := execution.StartProviders(ctx, &conf); err != nil {
 		log.Fatal(err)
 	}
 
 	log.Printf("execution lock:")
-	if err := execution.CreateExecutionLocks(&conf); err != nil {
-		log.Fatal(err)
-	}
+	locks := execution.CreateExecutionLocks(&conf)
 
 	log.Printf("filter partitions:")
 	if err := execution.FilterKeptPartitions(&conf); err != nil {
 		log.Fatal(err)
 	}
 
+	if conf.Mode == config.ApplyMode {
+		log.Printf("restrict to plan %q:", conf.PlanFile)
+		if err := execution.RestrictToPlan(&conf, plan, journal); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Printf("check safety guards:")
+	if err := execution.CheckSafetyGuards(&conf); err != nil {
+		log.Fatal(err)
+	}
+
 	log.Printf("check targets:")
-	if err := execution.CheckOperationTargets(&conf); err != nil {
+	if err := execution.CheckOperationTargets(&conf, journal); err != nil {
 		log.Fatal(err)
 	}
 
-	log.Printf("execute action:")
-	if err := execution.ExecuteArmedAction(&conf); err != nil {
+	log.Printf("prepare actions:")
+	report := &execution.ExecutionReport{}
+	preparedOperations, err := execution.PrepareActions(ctx, &conf, report)
+	if err != nil {
 		log.Fatal(err)
 	}
+	report.RecordListingRetries(conf.Providers)
+
+	switch conf.Mode {
+	case config.PlanMode:
+		plan = execution.MakePlan(&conf, preparedOperations)
+		if err := plan.Write(conf.PlanFile); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("wrote %d planned partition actions to %q", len(plan.Actions), conf.PlanFile)
+		if report.HasFailures() {
+			report.Log()
+			log.Fatalf("plan is missing partitions which failed to prepare (see report above)")
+		}
+		return
+	case config.ApplyMode:
+		if err := plan.CheckDrift(&conf, preparedOperations, journal); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Printf("execute action:")
+	execution.ExecuteArmedAction(ctx, &conf, preparedOperations, locks, journal, report)
+
+	report.Log()
+	if conf.ReportFile != "" {
+		if err := report.Write(conf.ReportFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if report.HasFailures() {
+		log.Fatalf("run finished with failures (see report above)")
+	}
 
 	log.Println("finished")
 	//for hash, partition := range conf.Resources["adpod_hourly"].GetPartitions() {
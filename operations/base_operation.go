@@ -8,9 +8,17 @@ import (
 )
 
 type BaseOperation struct {
-	Name      string
-	Excludes  []types.Exclude
-	DependsOn []string
+	Name        string
+	Excludes    []types.Exclude
+	DependsOn   []string
+	Concurrency int
+	// failing partitions get reported but do not stop the remaining partitions of this operation
+	ContinueOnError bool
+	// unset fields are inherited from the policy of the source provider
+	RetryPolicy types.RetryPolicy
+	Guards      SafetyGuards
+	// unset fields are inherited from the global dependency timeout
+	DependencyTimeout types.DependencyTimeout
 }
 
 func (operation BaseOperation) GetOperationName() string {
@@ -21,14 +29,42 @@ func (operation BaseOperation) GetDependencies() []string {
 	return operation.DependsOn
 }
 
+func (operation BaseOperation) GetContinueOnError() bool {
+	return operation.ContinueOnError
+}
+
+func (operation BaseOperation) GetRetryPolicy() types.RetryPolicy {
+	return operation.RetryPolicy
+}
+
+func (operation BaseOperation) GetDependencyTimeout() types.DependencyTimeout {
+	return operation.DependencyTimeout
+}
+
+// resources the excludes compare the partitions with (see types.ResourceReferrer)
+func (operation BaseOperation) ReferencedResources() []types.RuntimeResource {
+	return types.ReferencedResources(operation.Excludes...)
+}
+
+// zero means the global action concurrency applies
+func (operation BaseOperation) GetConcurrency() int {
+	return operation.Concurrency
+}
+
 // dont check dependencies hiere since all runtime operations musst be parsed first
 func makeBaseOperation(conf map[string]interface{}, resources map[string]types.RuntimeResource) (BaseOperation, error) {
 	var (
-		val          interface{}
-		ok           bool
-		name         string
-		excludes     []types.Exclude
-		dependencies []string
+		val             interface{}
+		ok              bool
+		name            string
+		excludes        []types.Exclude
+		dependencies    []string
+		concurrency     int
+		continueOnError bool
+		retryPolicy     types.RetryPolicy
+		guards          SafetyGuards
+		timeout         types.DependencyTimeout
+		err             error
 	)
 
 	if val, ok = conf["name"]; !ok {
@@ -54,6 +90,36 @@ func makeBaseOperation(conf map[string]interface{}, resources map[string]types.R
 		}
 	}
 
+	if val, ok = conf["concurrency"]; ok {
+		if concurrency, ok = types.ConfigInt(val); !ok || concurrency < 1 {
+			return BaseOperation{}, fmt.Errorf("\"concurrency\" field of operation %q is not a positive integer", name)
+		}
+	}
+
+	if val, ok = conf["continueonerror"]; ok {
+		if continueOnError, ok = val.(bool); !ok {
+			return BaseOperation{}, fmt.Errorf("\"continueonerror\" field of operation %q is not a boolean", name)
+		}
+	}
+
+	if val, ok = conf["retry"]; ok {
+		retryConf, ok := val.(map[string]interface{})
+		if !ok {
+			return BaseOperation{}, fmt.Errorf("\"retry\" field of operation %q is not a map", name)
+		}
+		if retryPolicy, err = types.MakeRetryPolicy(retryConf, fmt.Sprintf("operation %q", name)); err != nil {
+			return BaseOperation{}, err
+		}
+	}
+
+	if guards, err = makeSafetyGuards(conf, name); err != nil {
+		return BaseOperation{}, err
+	}
+
+	if timeout, err = types.MakeDependencyTimeout(conf, fmt.Sprintf("operation %q", name)); err != nil {
+		return BaseOperation{}, err
+	}
+
 	if val, ok = conf["exclude"]; ok {
 		rawExcludes, ok := val.([]interface{})
 		if !ok {
@@ -67,7 +133,7 @@ func makeBaseOperation(conf map[string]interface{}, resources map[string]types.R
 				return BaseOperation{}, fmt.Errorf("exclude  number %q of operation %q is not of a map", idx, name)
 			}
 
-			exclude, err := exclude.MakeExclude(name, excludeConf)
+			exclude, err := exclude.MakeExclude(name, excludeConf, resources)
 			if err != nil {
 				return BaseOperation{}, err
 			}
@@ -75,5 +141,14 @@ func makeBaseOperation(conf map[string]interface{}, resources map[string]types.R
 			excludes[idx] = exclude
 		}
 	}
-	return BaseOperation{name, excludes, dependencies}, nil
+	return BaseOperation{
+		Name:              name,
+		Excludes:          excludes,
+		DependsOn:         dependencies,
+		Concurrency:       concurrency,
+		ContinueOnError:   continueOnError,
+		RetryPolicy:       retryPolicy,
+		Guards:            guards,
+		DependencyTimeout: timeout,
+	}, nil
 }
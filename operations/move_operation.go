@@ -0,0 +1,37 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// copy and delete in one operation, the source partition is only removed once the copy is verified
+type MoveOperation struct {
+	ReplicateOperation
+}
+
+func makeMoveOperation(conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.RuntimeOperationSingle, error) {
+	// source and target are configured exactly like for copy
+	operation, err := makeReplicateOpeartion(conf, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MoveOperation{ReplicateOperation: *operation.(*ReplicateOperation)}, nil
+}
+
+func (operation MoveOperation) RemovesSource() bool {
+	return true
+}
+
+func (operation MoveOperation) ExecuteOperation(ctx context.Context) (types.PreparedActions, error) {
+	provider, ok := operation.source.GetProvider().(types.MoveProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider of resource %q does not implement move operation", operation.source.GetResourceName())
+	}
+
+	// partial results are kept for operations continuing on error
+	return provider.MovePartition(ctx, operation.keptPartitions, operation.source, operation.target)
+}
@@ -26,6 +26,21 @@ func (operation *OperationSingle) GetKeptPartitions() (types.PartitionList, erro
 	return operation.keptPartitions, nil
 }
 
+// overrides the excludes with a fixed partition selection (e.g. read from a plan file)
+func (operation *OperationSingle) SetKeptPartitions(partitions types.PartitionList) {
+	operation.keptPartitions = partitions
+}
+
+// compares the kept partitions against the configured safety guards
+func (operation *OperationSingle) CheckSafetyGuards() error {
+	kept, err := operation.GetKeptPartitions()
+	if err != nil {
+		return err
+	}
+
+	return operation.Guards.check(kept, len(operation.GetOperationSource().GetPartitions()))
+}
+
 func (operation *OperationSingle) PartitionsWithExcludes() error {
 	// get sorted list of partitions
 	partitions := operation.GetOperationSource().GetPartitions()
@@ -1,6 +1,7 @@
 package operations
 
 import (
+	"context"
 	"fmt"
 
 	"smartclip.de/cloud-cleaner/types"
@@ -39,15 +40,20 @@ func makeRemoveOpeartion(conf map[string]interface{}, resources map[string]types
 	return &moveOperation, nil
 }
 
-func (operation RemoveOperation) ExecuteOperation() (types.PreparedActions, error) {
+func (operation RemoveOperation) RemovesSource() bool {
+	return true
+}
+
+func (operation RemoveOperation) ExecuteOperation(ctx context.Context) (types.PreparedActions, error) {
 	provider, ok := operation.source.GetProvider().(types.RemoveProvider)
 	if !ok {
 		return nil, fmt.Errorf("provider of resource %q does not implement remove operation", operation.source.GetResourceName())
 	}
 
-	preparedAction, err := provider.RemovePartition(operation.keptPartitions, operation.source)
+	preparedAction, err := provider.RemovePartition(ctx, operation.keptPartitions, operation.source)
 	if err != nil {
-		return nil, err
+		// partial results are kept for operations continuing on error
+		return preparedAction, err
 	}
 
 	return preparedAction, nil
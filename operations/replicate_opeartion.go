@@ -1,6 +1,7 @@
 package operations
 
 import (
+	"context"
 	"fmt"
 
 	"smartclip.de/cloud-cleaner/types"
@@ -46,22 +47,24 @@ func makeReplicateOpeartion(conf map[string]interface{}, resources map[string]ty
 		return nil, fmt.Errorf("configured target %q of operation %q is no known resource", removeOperation.Name, resourceName)
 	}
 
-	if removeOperation.source.GetProvider().GetProviderName() != removeOperation.target.GetProvider().GetProviderName() {
-		return nil, fmt.Errorf("operation envolving source and target must have same provider (%q)", removeOperation.Name)
-	}
-
+	// source and target may belong to different providers, the source provider decides whether it can reach the target
 	return &removeOperation, nil
 }
 
-func (operation ReplicateOperation) ExecuteOperation() (types.PreparedActions, error) {
+func (operation ReplicateOperation) GetOperationTarget() types.RuntimeResource {
+	return operation.target
+}
+
+func (operation ReplicateOperation) ExecuteOperation(ctx context.Context) (types.PreparedActions, error) {
 	provider, ok := operation.source.GetProvider().(types.ReplicateProvider)
 	if !ok {
 		return nil, fmt.Errorf("provider of resource %q does not implement copy operation", operation.source.GetResourceName())
 	}
 
-	preparedAction, err := provider.CopyPartition(operation.keptPartitions, operation.source, operation.target)
+	preparedAction, err := provider.CopyPartition(ctx, operation.keptPartitions, operation.source, operation.target)
 	if err != nil {
-		return nil, err
+		// partial results are kept for operations continuing on error
+		return preparedAction, err
 	}
 
 	return preparedAction, nil
@@ -0,0 +1,102 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// requests temporary copies of archived objects (e.g. glacier) of the kept partitions
+type RestoreOperation struct {
+	OperationSingle
+	options types.RestoreOptions
+}
+
+func makeRestoreOperation(conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.RuntimeOperationSingle, error) {
+	var (
+		val          interface{}
+		ok           bool
+		resourceName string
+	)
+
+	baseOperation, err := makeBaseOperation(conf, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	restoreOperation := RestoreOperation{
+		OperationSingle: OperationSingle{BaseOperation: baseOperation},
+		options: types.RestoreOptions{
+			Days:         1,
+			Tier:         "Standard",
+			PollInterval: time.Minute,
+		},
+	}
+
+	if val, ok = conf["source"]; !ok {
+		return nil, fmt.Errorf("operation %q has no source configured", restoreOperation.Name)
+	}
+	if resourceName, ok = val.(string); !ok || resourceName == "" {
+		return nil, fmt.Errorf("\"source\" field of operation %q is not of type string", restoreOperation.Name)
+	}
+	if restoreOperation.source, ok = resources[resourceName]; !ok {
+		return nil, fmt.Errorf("configured source %q of operation %q is no known resource", resourceName, restoreOperation.Name)
+	}
+
+	if val, ok = conf["days"]; ok {
+		if restoreOperation.options.Days, ok = types.ConfigInt(val); !ok || restoreOperation.options.Days < 1 {
+			return nil, fmt.Errorf("\"days\" field of operation %q is not a positive integer", restoreOperation.Name)
+		}
+	}
+
+	if val, ok = conf["tier"]; ok {
+		tier, ok := val.(string)
+		if _, known := knownRestoreTiers[tier]; !ok || !known {
+			return nil, fmt.Errorf("\"tier\" field of operation %q is none of \"Standard\", \"Bulk\" or \"Expedited\"", restoreOperation.Name)
+		}
+		restoreOperation.options.Tier = tier
+	}
+
+	// waiting keeps the completion locks until the objects are readable so dependent operations can copy them
+	if val, ok = conf["wait"]; ok {
+		if restoreOperation.options.Wait, ok = val.(bool); !ok {
+			return nil, fmt.Errorf("\"wait\" field of operation %q is not a boolean", restoreOperation.Name)
+		}
+	}
+
+	if val, ok = conf["pollinterval"]; ok {
+		if restoreOperation.options.PollInterval, ok = types.ConfigDuration(val); !ok {
+			return nil, fmt.Errorf("\"pollinterval\" field of operation %q is not a positive duration string", restoreOperation.Name)
+		}
+	}
+
+	return &restoreOperation, nil
+}
+
+// worst case restore times of glacier flexible retrieval and deep archive (which has no expedited tier)
+var knownRestoreTiers = map[string]time.Duration{
+	"Standard":  12 * time.Hour,
+	"Bulk":      48 * time.Hour,
+	"Expedited": 5 * time.Minute,
+}
+
+// dependents of a waiting restore need a dependency timeout covering the restore (see config.getDependencyTimeouts)
+func (operation RestoreOperation) MaxWait() time.Duration {
+	if !operation.options.Wait {
+		return 0
+	}
+
+	return knownRestoreTiers[operation.options.Tier] + operation.options.PollInterval
+}
+
+func (operation RestoreOperation) ExecuteOperation(ctx context.Context) (types.PreparedActions, error) {
+	provider, ok := operation.source.GetProvider().(types.RestoreProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider of resource %q does not implement restore operation", operation.source.GetResourceName())
+	}
+
+	// partial results are kept for operations continuing on error
+	return provider.RestorePartition(ctx, operation.keptPartitions, operation.source, operation.options)
+}
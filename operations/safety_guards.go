@@ -0,0 +1,95 @@
+package operations
+
+import (
+	"errors"
+	"fmt"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// upper bounds of what a single operation may touch, zero values are not checked
+type SafetyGuards struct {
+	MaxPartitions int
+	MaxFraction   float64
+	MaxBytes      int64
+	MinRemaining  int
+}
+
+func makeSafetyGuards(conf map[string]interface{}, name string) (guards SafetyGuards, err error) {
+	if val, ok := conf["maxpartitions"]; ok {
+		if guards.MaxPartitions, ok = types.ConfigInt(val); !ok || guards.MaxPartitions < 1 {
+			return SafetyGuards{}, fmt.Errorf("\"maxpartitions\" field of operation %q is not a positive integer", name)
+		}
+	}
+
+	if val, ok := conf["maxfraction"]; ok {
+		if guards.MaxFraction, ok = types.ConfigFloat(val); !ok || guards.MaxFraction <= 0 || guards.MaxFraction > 1 {
+			return SafetyGuards{}, fmt.Errorf("\"maxfraction\" field of operation %q is not a number within (0, 1]", name)
+		}
+	}
+
+	if val, ok := conf["maxbytes"]; ok {
+		maxBytes, ok := types.ConfigFloat(val)
+		if !ok || maxBytes < 1 {
+			return SafetyGuards{}, fmt.Errorf("\"maxbytes\" field of operation %q is not a positive number", name)
+		}
+		guards.MaxBytes = int64(maxBytes)
+	}
+
+	if val, ok := conf["minremaining"]; ok {
+		if guards.MinRemaining, ok = types.ConfigInt(val); !ok || guards.MinRemaining < 1 {
+			return SafetyGuards{}, fmt.Errorf("\"minremaining\" field of operation %q is not a positive integer", name)
+		}
+	}
+
+	return
+}
+
+// every violated guard is reported, not just the first one
+func (guards SafetyGuards) check(kept types.PartitionList, total int) error {
+	var errs []error
+	affected := len(kept)
+
+	if guards.MaxPartitions > 0 && affected > guards.MaxPartitions {
+		errs = append(errs, fmt.Errorf(
+			"guard \"maxpartitions\" violated: %d partitions would be affected (limit %d)",
+			affected, guards.MaxPartitions,
+		))
+	}
+
+	if guards.MaxFraction > 0 && total > 0 {
+		if fraction := float64(affected) / float64(total); fraction > guards.MaxFraction {
+			errs = append(errs, fmt.Errorf(
+				"guard \"maxfraction\" violated: %d of %d partitions (%.1f%%) would be affected (limit %.1f%%)",
+				affected, total, fraction*100, guards.MaxFraction*100,
+			))
+		}
+	}
+
+	if guards.MinRemaining > 0 && total-affected < guards.MinRemaining {
+		errs = append(errs, fmt.Errorf(
+			"guard \"minremaining\" violated: only %d of %d partitions would remain (minimum %d)",
+			total-affected, total, guards.MinRemaining,
+		))
+	}
+
+	if guards.MaxBytes > 0 {
+		var bytes int64
+		for _, partition := range kept {
+			size, err := partition.GetSize()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("guard \"maxbytes\" can not be checked: %w", err))
+				break
+			}
+			bytes += size
+		}
+		if bytes > guards.MaxBytes {
+			errs = append(errs, fmt.Errorf(
+				"guard \"maxbytes\" violated: %d bytes would be affected (limit %d)",
+				bytes, guards.MaxBytes,
+			))
+		}
+	}
+
+	return errors.Join(errs...)
+}
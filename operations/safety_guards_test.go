@@ -0,0 +1,54 @@
+package operations
+
+import (
+	"testing"
+
+	"smartclip.de/cloud-cleaner/providers"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+func TestSafetyGuardsCheck(test *testing.T) {
+	// arrange
+	kept := types.PartitionList{
+		&providers.HivePartition{Size: 100},
+		&providers.HivePartition{Size: 100},
+		&providers.HivePartition{Size: 100},
+	}
+	testTabel := []struct {
+		name     string
+		guards   SafetyGuards
+		total    int
+		expected int
+	}{
+		{
+			name:     "no guards configured",
+			guards:   SafetyGuards{},
+			total:    3,
+			expected: 0,
+		},
+		{
+			name:     "all guards satisfied",
+			guards:   SafetyGuards{MaxPartitions: 3, MaxFraction: 0.5, MaxBytes: 300, MinRemaining: 3},
+			total:    6,
+			expected: 0,
+		},
+		{
+			name:     "whole table would be affected",
+			guards:   SafetyGuards{MaxPartitions: 2, MaxFraction: 0.5, MaxBytes: 200, MinRemaining: 1},
+			total:    3,
+			expected: 4,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			result := types.SplitErrors(subtest.guards.check(kept, subtest.total))
+
+			// assert
+			if len(result) != subtest.expected {
+				test.Errorf("test %q failed for %d != %d violations: %v", subtest.name, len(result), subtest.expected, result)
+			}
+		})
+	}
+}
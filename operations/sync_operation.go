@@ -0,0 +1,46 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// incremental copy which can be repeated for partially replicated partitions
+type SyncOperation struct {
+	ReplicateOperation
+	// target objects missing in the source get deleted
+	deleteExtra bool
+}
+
+func makeSyncOperation(conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.RuntimeOperationSingle, error) {
+	// source and target are configured exactly like for copy
+	operation, err := makeReplicateOpeartion(conf, resources)
+	if err != nil {
+		return nil, err
+	}
+	syncOperation := SyncOperation{ReplicateOperation: *operation.(*ReplicateOperation)}
+
+	if val, ok := conf["deleteextra"]; ok {
+		if syncOperation.deleteExtra, ok = val.(bool); !ok {
+			return nil, fmt.Errorf("\"deleteextra\" field of operation %q is not a boolean", syncOperation.Name)
+		}
+	}
+
+	return &syncOperation, nil
+}
+
+func (operation SyncOperation) ComparesTarget() bool {
+	return true
+}
+
+func (operation SyncOperation) ExecuteOperation(ctx context.Context) (types.PreparedActions, error) {
+	provider, ok := operation.source.GetProvider().(types.SyncProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider of resource %q does not implement sync operation", operation.source.GetResourceName())
+	}
+
+	// partial results are kept for operations continuing on error
+	return provider.SyncPartition(ctx, operation.keptPartitions, operation.source, operation.target, operation.deleteExtra)
+}
@@ -0,0 +1,98 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"text/template"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// adds, replaces or removes object tags of the kept partitions
+type TagOperation struct {
+	OperationSingle
+	options types.TagOptions
+}
+
+func makeTagOperation(conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.RuntimeOperationSingle, error) {
+	var (
+		val          interface{}
+		ok           bool
+		resourceName string
+	)
+
+	baseOperation, err := makeBaseOperation(conf, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	tagOperation := TagOperation{OperationSingle: OperationSingle{BaseOperation: baseOperation}}
+
+	if val, ok = conf["source"]; !ok {
+		return nil, fmt.Errorf("operation %q has no source configured", tagOperation.Name)
+	}
+	if resourceName, ok = val.(string); !ok || resourceName == "" {
+		return nil, fmt.Errorf("\"source\" field of operation %q is not of type string", tagOperation.Name)
+	}
+	if tagOperation.source, ok = resources[resourceName]; !ok {
+		return nil, fmt.Errorf("configured source %q of operation %q is no known resource", resourceName, tagOperation.Name)
+	}
+
+	if val, ok = conf["tags"]; ok {
+		rawTags, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("\"tags\" field of operation %q is not a map", tagOperation.Name)
+		}
+
+		tagOperation.options.Tags = make(map[string]*template.Template, len(rawTags))
+		for key, rawValue := range rawTags {
+			value, ok := rawValue.(string)
+			if !ok {
+				return nil, fmt.Errorf("tag %q of operation %q is not of type string", key, tagOperation.Name)
+			}
+
+			// unknown columns must not silently render as "<no value>"
+			valueTemplate, err := template.New(key).Option("missingkey=error").Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("tag %q of operation %q is no valid template: %w", key, tagOperation.Name, err)
+			}
+			tagOperation.options.Tags[key] = valueTemplate
+		}
+	}
+
+	if val, ok = conf["removetags"]; ok {
+		rawRemove, ok := val.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("\"removetags\" field of operation %q is not an array", tagOperation.Name)
+		}
+
+		tagOperation.options.Remove = make([]string, len(rawRemove))
+		for idx, rawKey := range rawRemove {
+			if tagOperation.options.Remove[idx], ok = rawKey.(string); !ok {
+				return nil, fmt.Errorf("\"removetags\" of operation %q contains a non string", tagOperation.Name)
+			}
+		}
+	}
+
+	if val, ok = conf["replace"]; ok {
+		if tagOperation.options.Replace, ok = val.(bool); !ok {
+			return nil, fmt.Errorf("\"replace\" field of operation %q is not a boolean", tagOperation.Name)
+		}
+	}
+
+	if len(tagOperation.options.Tags) == 0 && len(tagOperation.options.Remove) == 0 && !tagOperation.options.Replace {
+		return nil, fmt.Errorf("operation %q neither sets nor removes any tags", tagOperation.Name)
+	}
+
+	return &tagOperation, nil
+}
+
+func (operation TagOperation) ExecuteOperation(ctx context.Context) (types.PreparedActions, error) {
+	provider, ok := operation.source.GetProvider().(types.TagProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider of resource %q does not implement tag operation", operation.source.GetResourceName())
+	}
+
+	// partial results are kept for operations continuing on error
+	return provider.TagPartition(ctx, operation.keptPartitions, operation.source, operation.options)
+}
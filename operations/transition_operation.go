@@ -0,0 +1,83 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// rewrites the objects of the kept partitions in place with another storage class
+type TransitionOperation struct {
+	OperationSingle
+	storageClass string
+	// smaller objects are left untouched (e.g. because of minimum billable sizes)
+	minObjectSize int64
+}
+
+func makeTransitionOperation(conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.RuntimeOperationSingle, error) {
+	var (
+		val          interface{}
+		ok           bool
+		resourceName string
+	)
+
+	baseOperation, err := makeBaseOperation(conf, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	transitionOperation := TransitionOperation{OperationSingle: OperationSingle{BaseOperation: baseOperation}}
+
+	if val, ok = conf["source"]; !ok {
+		return nil, fmt.Errorf("operation %q has no source configured", transitionOperation.Name)
+	}
+	if resourceName, ok = val.(string); !ok || resourceName == "" {
+		return nil, fmt.Errorf("\"source\" field of operation %q is not of type string", transitionOperation.Name)
+	}
+	if transitionOperation.source, ok = resources[resourceName]; !ok {
+		return nil, fmt.Errorf("configured source %q of operation %q is no known resource", resourceName, transitionOperation.Name)
+	}
+
+	if val, ok = conf["storageclass"]; !ok {
+		return nil, fmt.Errorf("operation %q has no storageclass configured", transitionOperation.Name)
+	}
+	if transitionOperation.storageClass, ok = val.(string); !ok || transitionOperation.storageClass == "" {
+		return nil, fmt.Errorf("\"storageclass\" field of operation %q is not of type string", transitionOperation.Name)
+	}
+	if !knownStorageClass(transitionOperation.storageClass) {
+		return nil, fmt.Errorf("\"storageclass\" field of operation %q is none of %v", transitionOperation.Name, s3Types.StorageClass("").Values())
+	}
+
+	if val, ok = conf["minobjectsize"]; ok {
+		minObjectSize, ok := types.ConfigFloat(val)
+		if !ok || minObjectSize < 0 {
+			return nil, fmt.Errorf("\"minobjectsize\" field of operation %q is not a positive number", transitionOperation.Name)
+		}
+		transitionOperation.minObjectSize = int64(minObjectSize)
+	}
+
+	return &transitionOperation, nil
+}
+
+func knownStorageClass(storageClass string) bool {
+	for _, known := range s3Types.StorageClass("").Values() {
+		if string(known) == storageClass {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (operation TransitionOperation) ExecuteOperation(ctx context.Context) (types.PreparedActions, error) {
+	provider, ok := operation.source.GetProvider().(types.TransitionProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider of resource %q does not implement transition operation", operation.source.GetResourceName())
+	}
+
+	// partial results are kept for operations continuing on error
+	return provider.TransitionPartition(ctx, operation.keptPartitions, operation.source, operation.storageClass, operation.minObjectSize)
+}
@@ -6,11 +6,23 @@ const (
 	UnknowAction types.Action = ""
 	Replicate                 = "copy"   // copy is go internal name
 	Remove                    = "delete" // delete is go internal name
+	Move                      = "move"
+	Transition                = "transition"
+	Restore                   = "restore"
+	Verify                    = "verify"
+	Sync                      = "sync"
+	Tag                       = "tag"
 )
 
 type makeOperation map[types.Action]func(map[string]interface{}, map[string]types.RuntimeResource) (types.RuntimeOperationSingle, error)
 
 var KnownActions makeOperation = makeOperation{
-	Replicate: makeReplicateOpeartion,
-	Remove:    makeRemoveOpeartion,
+	Replicate:  makeReplicateOpeartion,
+	Remove:     makeRemoveOpeartion,
+	Move:       makeMoveOperation,
+	Transition: makeTransitionOperation,
+	Restore:    makeRestoreOperation,
+	Verify:     makeVerifyOperation,
+	Sync:       makeSyncOperation,
+	Tag:        makeTagOperation,
 }
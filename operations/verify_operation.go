@@ -0,0 +1,40 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// read only comparison of the kept partitions of source and target (e.g. after a copy)
+type VerifyOperation struct {
+	ReplicateOperation
+}
+
+func makeVerifyOperation(conf map[string]interface{}, resources map[string]types.RuntimeResource) (types.RuntimeOperationSingle, error) {
+	// source and target are configured exactly like for copy
+	operation, err := makeReplicateOpeartion(conf, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VerifyOperation{ReplicateOperation: *operation.(*ReplicateOperation)}, nil
+}
+
+func (operation VerifyOperation) ReadOnly() bool {
+	return true
+}
+
+func (operation VerifyOperation) ComparesTarget() bool {
+	return true
+}
+
+func (operation VerifyOperation) ExecuteOperation(ctx context.Context) (types.PreparedActions, error) {
+	provider, ok := operation.source.GetProvider().(types.VerifyProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider of resource %q does not implement verify operation", operation.source.GetResourceName())
+	}
+
+	return provider.VerifyPartition(ctx, operation.keptPartitions, operation.source, operation.target)
+}
@@ -92,3 +92,15 @@ func ParsePartitionString(specs []types.PartitionSpec, partition []string) (type
 
 	return parsedValues, nil
 }
+
+// time of date and datetime partition values, false for every other data type
+func ValueTime(val types.TypedPartitionValue) (time.Time, bool) {
+	switch typed := val.(type) {
+	case dateValue:
+		return typed.Time, true
+	case dateTimeValue:
+		return typed.Time, true
+	default:
+		return time.Time{}, false
+	}
+}
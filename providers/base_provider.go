@@ -3,6 +3,7 @@ package providers
 import (
 	"fmt"
 	"log"
+	"sync/atomic"
 
 	"smartclip.de/cloud-cleaner/types"
 )
@@ -13,6 +14,12 @@ type BaseProvider struct {
 	InputChan    chan types.RuntimeResource
 	Resources    []types.RuntimeResource
 	Concurrency  int
+	// upper bound of partition actions running at once on this provider (0 is unbounded)
+	ActionConcurrency int
+	// unset fields are inherited from the global retry policy
+	RetryPolicy types.RetryPolicy
+	// shared by all copies of the provider (s3 providers use value receivers)
+	listingRetries *atomic.Int64
 }
 
 func (client *BaseProvider) GetProviderName() string {
@@ -35,6 +42,23 @@ func (provider *BaseProvider) GetResourceConcurrency() int {
 	return provider.Concurrency
 }
 
+func (provider *BaseProvider) GetActionConcurrency() int {
+	return provider.ActionConcurrency
+}
+
+func (provider *BaseProvider) GetRetryPolicy() types.RetryPolicy {
+	return provider.RetryPolicy
+}
+
+func (provider *BaseProvider) InheritRetryPolicy(parent types.RetryPolicy) {
+	provider.RetryPolicy = provider.RetryPolicy.Inherit(parent)
+}
+
+// number of retried listing requests during partition collection and preparation
+func (provider *BaseProvider) GetListingRetries() int64 {
+	return provider.listingRetries.Load()
+}
+
 func MakeBaseProvider(conf map[string]interface{}) (base BaseProvider, err error) {
 	var (
 		val interface{}
@@ -73,5 +97,39 @@ func MakeBaseProvider(conf map[string]interface{}) (base BaseProvider, err error
 		}
 	}
 
+	if val, ok := conf["actionconcurrency"]; ok {
+		if concurrency, ok := types.ConfigInt(val); !ok || concurrency < 1 {
+			return BaseProvider{}, fmt.Errorf("actionconcurrency of provider %q is not a positive integer", base.Name)
+		} else {
+			base.ActionConcurrency = concurrency
+		}
+	}
+
+	if val, ok := conf["retry"]; ok {
+		retryConf, ok := val.(map[string]interface{})
+		if !ok {
+			return BaseProvider{}, fmt.Errorf("retry field of provider %q is not a map", base.Name)
+		}
+		if base.RetryPolicy, err = types.MakeRetryPolicy(retryConf, fmt.Sprintf("provider %q", base.Name)); err != nil {
+			return BaseProvider{}, err
+		}
+	}
+	base.listingRetries = &atomic.Int64{}
+
 	return
 }
+
+// optional token bucket limit, nil if the field is not configured
+func makeRateLimiter(conf map[string]interface{}, field string, providerName string) (*types.RateLimiter, error) {
+	val, ok := conf[field]
+	if !ok {
+		return nil, nil
+	}
+
+	perSecond, ok := types.ConfigFloat(val)
+	if !ok || perSecond <= 0 {
+		return nil, fmt.Errorf("%s of provider %q is not a positive number", field, providerName)
+	}
+
+	return types.NewRateLimiter(perSecond), nil
+}
@@ -1,17 +1,24 @@
 package providers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"smartclip.de/cloud-cleaner/types"
 )
 
 func (provider S3Provider) CopyPartition(
+	ctx context.Context,
 	partititons types.PartitionList,
 	source types.RuntimeResource,
 	target types.RuntimeResource,
@@ -24,77 +31,45 @@ func (provider S3Provider) CopyPartition(
 	mutex := &sync.Mutex{}
 	errChan := make(chan error)
 	sourceResource, _ := source.(S3Resource) // error case handled at resource creation
-	targetResource, _ := target.(S3Resource) // error case handled at resource creation
+	targetResource, ok := target.(S3Resource)
+	if !ok {
+		return nil, fmt.Errorf("target %q is no s3 resource", target.GetResourceName())
+	}
 	sourceBucket, sourcePrefix, err := splitBucketAndKey(sourceResource.getPrefix())
 	if err != nil {
 		return nil, err
 	}
+	targetProvider, err := targetS3Provider(target)
+	if err != nil {
+		return nil, err
+	}
 
-	sourcePartitionSpec := source.GetPartitionSpec()
-	sourcePartitionKeys := make([]string, len(sourcePartitionSpec))
 	for _, partition := range partititons {
 		wg.Add(1)
 		go func(partition types.Partition) {
 			defer wg.Done()
-			partitionValues := partition.GetValues()
 
-			for idx, spec := range sourcePartitionSpec {
-				sourcePartitionKeys[idx] = spec.Name + "=" + partitionValues[idx]
+			s3Objects, err := provider.listPartitionObjects(ctx, sourceBucket, partitionKey(sourcePrefix, source, partition))
+			if err != nil {
+				errChan <- prepareError(source, partition, err)
+				return
 			}
 
-			sourceKey := sourcePrefix + "/" + strings.Join(sourcePartitionKeys, "/")
-			listPrefix := s3.ListObjectsV2Input{
-				Bucket:  &sourceBucket,
-				Prefix:  &sourceKey,
-				MaxKeys: 100, // TODO: make configurable
-			}
-			listObjectOutput := &s3.ListObjectsV2Output{IsTruncated: true}
-			var singleObjectActions []func() error
-			for listObjectOutput.IsTruncated {
-				if listObjectOutput, err = provider.s3Client.listS3(&listPrefix); err != nil {
-					errChan <- err
+			var preparedObjects []types.PreparedObject
+			for _, s3Object := range s3Objects {
+				targetPrefix := targetResource.getPrefix() + strings.TrimPrefix(*s3Object.Key, sourcePrefix)
+				preparedObject, err := provider.preparedCopy(ctx, targetProvider, sourceBucket, s3Object, targetPrefix)
+				if err != nil {
+					errChan <- prepareError(source, partition, err)
 					return
 				}
-				listPrefix.ContinuationToken = listObjectOutput.NextContinuationToken
-
-				for _, s3Object := range listObjectOutput.Contents {
-					sourceObjectKey := sourceBucket + "/" + *s3Object.Key
-					targetPrefix := targetResource.getPrefix() + strings.TrimPrefix(*s3Object.Key, sourcePrefix)
-					targetBucket, targetKey, err := splitBucketAndKey(targetPrefix)
-					if err != nil {
-						errChan <- err
-						return
-					}
-
-					log.Printf("preparing cp: s3://%s -> s3://%s/%s", sourceObjectKey, targetBucket, targetKey)
-
-					copyInput := &s3.CopyObjectInput{
-						CopySource: aws.String(url.PathEscape(sourceObjectKey)),
-						Bucket:     aws.String(targetBucket),
-						Key:        aws.String(targetKey),
-					}
-					// TODO: existing files get overwritten -> check why 'check target' not works
-					singleObjectActions = append(singleObjectActions, func() error {
-						log.Printf("executing cp: s3://%s -> s3://%s/%s", sourceObjectKey, targetBucket, targetKey)
-						return provider.s3Client.copy(copyInput)
-					})
-				}
-			}
-
-			action := func() error {
-				for _, objectAction := range singleObjectActions {
-					if err := objectAction(); err != nil {
-						return err
-					}
-				}
-
-				return nil
+				preparedObjects = append(preparedObjects, preparedObject)
 			}
 
 			mutex.Lock()
 			preparedActions = append(preparedActions, types.PreparedPartitionAction{
 				Partition: partition,
-				Action:    action,
+				Objects:   preparedObjects,
 			})
 			mutex.Unlock()
 		}(partition)
@@ -105,15 +80,60 @@ func (provider S3Provider) CopyPartition(
 		close(errChan)
 	}()
 
-	if err = <-errChan; err != nil {
-		return nil, err
+	// failing partitions are reported all together, the healthy ones are still returned
+	err = types.CollectErrors(errChan)
+
+	return preparedActions, err
+}
+
+// copy of a single object to the full s3 url target of the target provider
+// server side if one set of credentials reaches both sides, otherwise streamed through this process
+func (provider S3Provider) preparedCopy(
+	ctx context.Context,
+	targetProvider S3Provider,
+	sourceBucket string,
+	s3Object s3Types.Object,
+	target string,
+) (types.PreparedObject, error) {
+	sourceObjectKey := sourceBucket + "/" + *s3Object.Key
+	targetBucket, targetKey, err := splitBucketAndKey(target)
+	if err != nil {
+		return types.PreparedObject{}, err
 	}
 
-	return preparedActions, nil
+	preparedObject := preparedS3Object(sourceBucket, s3Object)
+	preparedObject.Target = target
+
+	if !provider.sharesCredentials(ctx, targetProvider) {
+		log.Printf("preparing streamed cp: s3://%s -> s3://%s/%s (provider %q -> %q)", sourceObjectKey, targetBucket, targetKey, provider.Name, targetProvider.Name)
+		preparedObject.Action = func() error {
+			log.Printf("executing streamed cp: s3://%s -> s3://%s/%s", sourceObjectKey, targetBucket, targetKey)
+			return provider.streamCopy(ctx, targetProvider, sourceBucket, *s3Object.Key, s3Object.Size, targetBucket, targetKey)
+		}
+		return preparedObject, nil
+	}
+
+	log.Printf("preparing cp: s3://%s -> s3://%s/%s", sourceObjectKey, targetBucket, targetKey)
+
+	copyInput := &s3.CopyObjectInput{
+		CopySource: aws.String(url.PathEscape(sourceObjectKey)),
+		Bucket:     aws.String(targetBucket),
+		Key:        aws.String(targetKey),
+	}
+
+	preparedObject.Action = provider.limited(ctx, s3Object.Size, func() error {
+		log.Printf("executing cp: s3://%s -> s3://%s/%s", sourceObjectKey, targetBucket, targetKey)
+		return provider.s3Client.copy(ctx, copyInput)
+	})
+
+	return preparedObject, nil
 }
 
-func (provider S3Provider) RemovePartition(partititons types.PartitionList, source types.RuntimeResource) (types.PreparedActions, error) {
-	var preparedActions types.PreparedActions
+func (provider S3Provider) RemovePartition(ctx context.Context, partititons types.PartitionList, source types.RuntimeResource) (types.PreparedActions, error) {
+	var (
+		preparedActions types.PreparedActions
+		errs            []error
+	)
 
 	sourceResource, _ := source.(S3Resource) // error case handled at resource creation
 	sourceBucket, sourcePrefix, err := splitBucketAndKey(sourceResource.getPrefix())
@@ -121,62 +141,642 @@ func (provider S3Provider) RemovePartition(partititons types.PartitionList, sour
 		return nil, err
 	}
 
-	sourcePartitionSpec := source.GetPartitionSpec()
-	sourcePartitionKeys := make([]string, len(sourcePartitionSpec))
 	// TODO: implement async partition processing like in copy partitions (don't forget append mutex)
 	for _, partition := range partititons {
-		partition.GetDependencies()
-		partitionValues := partition.GetValues()
+		s3Objects, err := provider.listPartitionObjects(ctx, sourceBucket, partitionKey(sourcePrefix, source, partition))
+		if err != nil {
+			errs = append(errs, prepareError(source, partition, err))
+			continue
+		}
+
+		var preparedObjects []types.PreparedObject
+		for _, s3Object := range s3Objects {
+			objectKey := s3Object.Key
+			log.Printf("preparing rm: s3://%s", sourceBucket+"/"+*objectKey)
+
+			deleteInput := &s3.DeleteObjectInput{
+				Bucket: aws.String(sourceBucket),
+				Key:    objectKey,
+			}
 
-		for idx, spec := range sourcePartitionSpec {
-			sourcePartitionKeys[idx] = spec.Name + "=" + partitionValues[idx]
+			preparedObject := preparedS3Object(sourceBucket, s3Object)
+			preparedObject.Action = provider.limited(ctx, 0, func() error {
+				log.Printf("executing rm: s3://%s", sourceBucket+"/"+*objectKey)
+				return provider.s3Client.delete(ctx, deleteInput)
+			})
+			preparedObjects = append(preparedObjects, preparedObject)
 		}
 
-		sourceKey := sourcePrefix + "/" + strings.Join(sourcePartitionKeys, "/")
-		listPrefix := s3.ListObjectsV2Input{
-			Bucket:  &sourceBucket,
-			Prefix:  &sourceKey,
-			MaxKeys: 100, // TODO: make configurable
+		preparedActions = append(preparedActions, types.PreparedPartitionAction{
+			Partition: partition,
+			Objects:   preparedObjects,
+		})
+	}
+
+	return preparedActions, errors.Join(errs...)
+}
+
+// copies all objects of a partition, verifies them in the target and only then deletes the source objects
+// the whole partition is one action so a failing copy or verification never leads to deleted source objects
+func (provider S3Provider) MovePartition(
+	ctx context.Context,
+	partititons types.PartitionList,
+	source types.RuntimeResource,
+	target types.RuntimeResource,
+) (types.PreparedActions, error) {
+	preparedActions, err := provider.CopyPartition(ctx, partititons, source, target)
+	// the copies are verified with the client of the target
+	targetProvider, targetErr := targetS3Provider(target)
+	if targetErr != nil {
+		return nil, targetErr
+	}
+
+	for idx := range preparedActions {
+		objects := preparedActions[idx].Objects
+		copyActions := make([]func() error, len(objects))
+		for objectIdx := range objects {
+			copyActions[objectIdx] = objects[objectIdx].Action
+			objects[objectIdx].Action = nil
 		}
-		listObjectOutput := &s3.ListObjectsV2Output{IsTruncated: true}
 
-		var singleObjectActions []func() error
-		for listObjectOutput.IsTruncated {
-			if listObjectOutput, err = provider.s3Client.listS3(&listPrefix); err != nil {
-				return nil, err
+		preparedActions[idx].Action = func() error {
+			for objectIdx, copyAction := range copyActions {
+				if err := copyAction(); err != nil {
+					// an interrupted earlier attempt may already have deleted the source of a verified copy
+					if !isErrorCode(err, "NoSuchKey") || targetProvider.verifyCopy(ctx, objects[objectIdx]) != nil {
+						return err
+					}
+				}
 			}
-			listPrefix.ContinuationToken = listObjectOutput.NextContinuationToken
 
-			for _, s3Object := range listObjectOutput.Contents {
-				log.Printf("preparing rm: s3://%s", sourceBucket+"/"+*s3Object.Key)
+			for _, object := range objects {
+				if err := targetProvider.verifyCopy(ctx, object); err != nil {
+					return err
+				}
+			}
 
-				deleteInput := &s3.DeleteObjectInput{
-					Bucket: aws.String(sourceBucket),
-					Key:    s3Object.Key,
+			for _, object := range objects {
+				deleteAction, err := provider.deleteAction(ctx, object.Key)
+				if err != nil {
+					return err
+				}
+				if err := deleteAction(); err != nil {
+					return err
 				}
+			}
+
+			return nil
+		}
+	}
+
+	return preparedActions, err
+}
+
+// compares size and etag of the copied object with its source, called on the provider of the target
+func (provider S3Provider) verifyCopy(ctx context.Context, object types.PreparedObject) error {
+	bucket, key, err := splitBucketAndKey(object.Target)
+	if err != nil {
+		return err
+	}
+
+	var head *s3.HeadObjectOutput
+	err = provider.limited(ctx, 0, func() (err error) {
+		head, err = provider.s3Client.head(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+		return
+	})()
+	if err != nil {
+		return fmt.Errorf("could not verify copy %q: %w", object.Target, err)
+	}
+
+	if head.ContentLength != object.Size {
+		return fmt.Errorf("copy %q has %d bytes instead of %d", object.Target, head.ContentLength, object.Size)
+	}
+	if comparableETags(object.ETag, aws.ToString(head.ETag)) && aws.ToString(head.ETag) != object.ETag {
+		return fmt.Errorf("copy %q has etag %s instead of %s", object.Target, aws.ToString(head.ETag), object.ETag)
+	}
+
+	return nil
+}
+
+// a multipart etag ("<md5>-<parts>") depends on the part sizes and is neither kept by CopyObject
+// nor reproduced by a streamed copy, only the size can be compared then
+func comparableETags(sourceETag string, targetETag string) bool {
+	return !strings.Contains(sourceETag, "-") && !strings.Contains(targetETag, "-")
+}
+
+func isErrorCode(err error, code string) bool {
+	var apiErr interface{ ErrorCode() string }
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
+
+// rewrites every object in place via CopyObject with the new storage class
+func (provider S3Provider) TransitionPartition(
+	ctx context.Context,
+	partititons types.PartitionList,
+	source types.RuntimeResource,
+	storageClass string,
+	minObjectSize int64,
+) (types.PreparedActions, error) {
+	var (
+		preparedActions types.PreparedActions
+		errs            []error
+	)
+
+	sourceResource, _ := source.(S3Resource) // error case handled at resource creation
+	sourceBucket, sourcePrefix, err := splitBucketAndKey(sourceResource.getPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, partition := range partititons {
+		s3Objects, err := provider.listPartitionObjects(ctx, sourceBucket, partitionKey(sourcePrefix, source, partition))
+		if err != nil {
+			errs = append(errs, prepareError(source, partition, err))
+			continue
+		}
 
-				singleObjectActions = append(singleObjectActions, func() error {
-					log.Printf("executing rm: s3://%s", sourceBucket+"/"+*s3Object.Key)
-					return provider.s3Client.delete(deleteInput)
-				})
+		var preparedObjects []types.PreparedObject
+		for _, s3Object := range s3Objects {
+			// listings report STANDARD objects without storage class
+			currentClass := string(s3Object.StorageClass)
+			if currentClass == "" {
+				currentClass = string(s3Types.StorageClassStandard)
 			}
+			if s3Object.Size < minObjectSize || currentClass == storageClass {
+				continue
+			}
+
+			objectKey := sourceBucket + "/" + *s3Object.Key
+			log.Printf("preparing transition: s3://%s (%s -> %s)", objectKey, currentClass, storageClass)
+
+			copyInput := &s3.CopyObjectInput{
+				CopySource:   aws.String(url.PathEscape(objectKey)),
+				Bucket:       aws.String(sourceBucket),
+				Key:          s3Object.Key,
+				StorageClass: s3Types.StorageClass(storageClass),
+			}
+
+			preparedObject := preparedS3Object(sourceBucket, s3Object)
+			preparedObject.Action = provider.limited(ctx, s3Object.Size, func() error {
+				log.Printf("executing transition: s3://%s -> %s", objectKey, storageClass)
+				return provider.s3Client.copy(ctx, copyInput)
+			})
+			preparedObjects = append(preparedObjects, preparedObject)
 		}
 
-		action := func() error {
-			for _, action := range singleObjectActions {
-				if err := action(); err != nil {
-					return err
+		preparedActions = append(preparedActions, types.PreparedPartitionAction{
+			Partition: partition,
+			Objects:   preparedObjects,
+		})
+	}
+
+	return preparedActions, errors.Join(errs...)
+}
+
+// requests restores of all archived objects, in wait mode the partition action only returns once they are readable
+func (provider S3Provider) RestorePartition(
+	ctx context.Context,
+	partititons types.PartitionList,
+	source types.RuntimeResource,
+	options types.RestoreOptions,
+) (types.PreparedActions, error) {
+	var (
+		preparedActions types.PreparedActions
+		errs            []error
+	)
+
+	sourceResource, _ := source.(S3Resource) // error case handled at resource creation
+	sourceBucket, sourcePrefix, err := splitBucketAndKey(sourceResource.getPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, partition := range partititons {
+		s3Objects, err := provider.listPartitionObjects(ctx, sourceBucket, partitionKey(sourcePrefix, source, partition))
+		if err != nil {
+			errs = append(errs, prepareError(source, partition, err))
+			continue
+		}
+
+		var preparedObjects []types.PreparedObject
+		for _, s3Object := range s3Objects {
+			// objects in all other classes are readable right away
+			if s3Object.StorageClass != s3Types.ObjectStorageClassGlacier && s3Object.StorageClass != s3Types.ObjectStorageClassDeepArchive {
+				continue
+			}
+
+			objectKey := sourceBucket + "/" + *s3Object.Key
+			log.Printf("preparing restore: s3://%s (%d days, tier %s)", objectKey, options.Days, options.Tier)
+
+			restoreInput := &s3.RestoreObjectInput{
+				Bucket: aws.String(sourceBucket),
+				Key:    s3Object.Key,
+				RestoreRequest: &s3Types.RestoreRequest{
+					Days:                 int32(options.Days),
+					GlacierJobParameters: &s3Types.GlacierJobParameters{Tier: s3Types.Tier(options.Tier)},
+				},
+			}
+
+			preparedObject := preparedS3Object(sourceBucket, s3Object)
+			preparedObject.Action = provider.limited(ctx, 0, func() error {
+				log.Printf("executing restore: s3://%s", objectKey)
+				err := provider.s3Client.restore(ctx, restoreInput)
+				if isErrorCode(err, "RestoreAlreadyInProgress") {
+					return nil
 				}
+				return err
+			})
+			preparedObjects = append(preparedObjects, preparedObject)
+		}
+
+		preparedAction := types.PreparedPartitionAction{
+			Partition: partition,
+			Objects:   preparedObjects,
+		}
+		if options.Wait {
+			preparedAction.Action = provider.restoreAndWait(ctx, preparedObjects, options.PollInterval)
+		}
+		preparedActions = append(preparedActions, preparedAction)
+	}
+
+	return preparedActions, errors.Join(errs...)
+}
+
+// all restores are requested first so they are processed in parallel, afterwards every object is polled
+func (provider S3Provider) restoreAndWait(ctx context.Context, objects []types.PreparedObject, pollInterval time.Duration) func() error {
+	return func() error {
+		for _, object := range objects {
+			if err := object.Action(); err != nil {
+				return err
 			}
+		}
 
-			return nil
+		for _, object := range objects {
+			bucket, key, err := splitBucketAndKey(object.Key)
+			if err != nil {
+				return err
+			}
+			headInput := &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}
+
+			for {
+				var head *s3.HeadObjectOutput
+				err = provider.limited(ctx, 0, func() (err error) {
+					head, err = provider.s3Client.head(ctx, headInput)
+					return
+				})()
+				if err != nil {
+					return fmt.Errorf("could not poll restore of %q: %w", object.Key, err)
+				}
+				// e.g. 'ongoing-request="false", expiry-date="..."' once restored
+				if strings.Contains(aws.ToString(head.Restore), `ongoing-request="false"`) {
+					break
+				}
+
+				log.Printf("waiting %s for restore of %q", pollInterval, object.Key)
+				select {
+				case <-time.After(pollInterval):
+				case <-ctx.Done():
+					return fmt.Errorf("waiting for restore of %q aborted: %w", object.Key, context.Cause(ctx))
+				}
+			}
 		}
 
+		return nil
+	}
+}
+
+// the comparison itself is the action so it sees the target after all dependencies (e.g. a copy) completed
+func (provider S3Provider) VerifyPartition(
+	ctx context.Context,
+	partititons types.PartitionList,
+	source types.RuntimeResource,
+	target types.RuntimeResource,
+) (types.PreparedActions, error) {
+	var preparedActions types.PreparedActions
+
+	sourceResource, _ := source.(S3Resource) // error case handled at resource creation
+	targetResource, ok := target.(S3Resource)
+	if !ok {
+		return nil, fmt.Errorf("target %q is no s3 resource", target.GetResourceName())
+	}
+	sourceBucket, sourcePrefix, err := splitBucketAndKey(sourceResource.getPrefix())
+	if err != nil {
+		return nil, err
+	}
+	targetBucket, targetPrefix, err := splitBucketAndKey(targetResource.getPrefix())
+	if err != nil {
+		return nil, err
+	}
+	targetProvider, err := targetS3Provider(target)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, partition := range partititons {
+		sourceKey := partitionKey(sourcePrefix, source, partition)
+		targetKey := partitionKey(targetPrefix, target, partition)
+		log.Printf("preparing verify: s3://%s/%s <-> s3://%s/%s", sourceBucket, sourceKey, targetBucket, targetKey)
+
 		preparedActions = append(preparedActions, types.PreparedPartitionAction{
 			Partition: partition,
-			Action:    action,
+			Action: func() error {
+				log.Printf("executing verify: s3://%s/%s <-> s3://%s/%s", sourceBucket, sourceKey, targetBucket, targetKey)
+				sourceObjects, err := provider.listPartitionObjects(ctx, sourceBucket, sourceKey)
+				if err != nil {
+					return err
+				}
+				targetObjects, err := targetProvider.listPartitionObjects(ctx, targetBucket, targetKey)
+				if err != nil {
+					return err
+				}
+
+				return compareObjects(sourceBucket, sourceKey, sourceObjects, targetBucket, targetKey, targetObjects)
+			},
 		})
 	}
 
 	return preparedActions, nil
 }
+
+// objects are matched by their key relative to the partition prefix
+func compareObjects(
+	sourceBucket string,
+	sourceKey string,
+	sourceObjects []s3Types.Object,
+	targetBucket string,
+	targetKey string,
+	targetObjects []s3Types.Object,
+) error {
+	diff := &types.DivergenceError{SourceObjects: len(sourceObjects), TargetObjects: len(targetObjects)}
+
+	targetByKey := make(map[string]s3Types.Object, len(targetObjects))
+	for _, targetObject := range targetObjects {
+		diff.TargetBytes += targetObject.Size
+		targetByKey[strings.TrimPrefix(*targetObject.Key, targetKey)] = targetObject
+	}
+
+	for _, sourceObject := range sourceObjects {
+		diff.SourceBytes += sourceObject.Size
+		relativeKey := strings.TrimPrefix(*sourceObject.Key, sourceKey)
+		divergence := types.ObjectDivergence{
+			Key:        "s3://" + sourceBucket + "/" + *sourceObject.Key,
+			Target:     "s3://" + targetBucket + "/" + targetKey + relativeKey,
+			SourceSize: sourceObject.Size,
+			SourceETag: aws.ToString(sourceObject.ETag),
+		}
+
+		targetObject, ok := targetByKey[relativeKey]
+		if !ok {
+			divergence.Kind = types.MissingObject
+			diff.Divergences = append(diff.Divergences, divergence)
+			continue
+		}
+		delete(targetByKey, relativeKey)
+
+		divergence.TargetSize = targetObject.Size
+		divergence.TargetETag = aws.ToString(targetObject.ETag)
+		switch {
+		case sourceObject.Size != targetObject.Size:
+			divergence.Kind = types.SizeMismatch
+		case comparableETags(divergence.SourceETag, divergence.TargetETag) && divergence.SourceETag != divergence.TargetETag:
+			divergence.Kind = types.ETagMismatch
+		default:
+			continue
+		}
+		diff.Divergences = append(diff.Divergences, divergence)
+	}
+
+	for relativeKey, targetObject := range targetByKey {
+		diff.Divergences = append(diff.Divergences, types.ObjectDivergence{
+			Kind:       types.ExtraObject,
+			Target:     "s3://" + targetBucket + "/" + targetKey + relativeKey,
+			TargetSize: targetObject.Size,
+			TargetETag: aws.ToString(targetObject.ETag),
+		})
+	}
+
+	if len(diff.Divergences) > 0 {
+		sort.Slice(diff.Divergences, func(i, j int) bool {
+			return diff.Divergences[i].Key+diff.Divergences[i].Target < diff.Divergences[j].Key+diff.Divergences[j].Target
+		})
+		return diff
+	}
+
+	return nil
+}
+
+// copies only objects missing or differing in the target, extra target objects are optionally deleted
+func (provider S3Provider) SyncPartition(
+	ctx context.Context,
+	partititons types.PartitionList,
+	source types.RuntimeResource,
+	target types.RuntimeResource,
+	deleteExtra bool,
+) (types.PreparedActions, error) {
+	var (
+		preparedActions types.PreparedActions
+		errs            []error
+	)
+
+	sourceResource, _ := source.(S3Resource) // error case handled at resource creation
+	targetResource, ok := target.(S3Resource)
+	if !ok {
+		return nil, fmt.Errorf("target %q is no s3 resource", target.GetResourceName())
+	}
+	sourceBucket, sourcePrefix, err := splitBucketAndKey(sourceResource.getPrefix())
+	if err != nil {
+		return nil, err
+	}
+	targetBucket, targetPrefix, err := splitBucketAndKey(targetResource.getPrefix())
+	if err != nil {
+		return nil, err
+	}
+	targetProvider, err := targetS3Provider(target)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, partition := range partititons {
+		preparedObjects, err := provider.prepareSync(ctx, targetProvider, sourceBucket, partitionKey(sourcePrefix, source, partition), targetBucket, partitionKey(targetPrefix, target, partition), deleteExtra)
+		if err != nil {
+			errs = append(errs, prepareError(source, partition, err))
+			continue
+		}
+
+		preparedActions = append(preparedActions, types.PreparedPartitionAction{
+			Partition: partition,
+			Objects:   preparedObjects,
+		})
+	}
+
+	return preparedActions, errors.Join(errs...)
+}
+
+func (provider S3Provider) prepareSync(
+	ctx context.Context,
+	targetProvider S3Provider,
+	sourceBucket string,
+	sourceKey string,
+	targetBucket string,
+	targetKey string,
+	deleteExtra bool,
+) ([]types.PreparedObject, error) {
+	sourceObjects, err := provider.listPartitionObjects(ctx, sourceBucket, sourceKey)
+	if err != nil {
+		return nil, err
+	}
+	targetObjects, err := targetProvider.listPartitionObjects(ctx, targetBucket, targetKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff *types.DivergenceError
+	if !errors.As(compareObjects(sourceBucket, sourceKey, sourceObjects, targetBucket, targetKey, targetObjects), &diff) {
+		log.Printf("sync of s3://%s/%s is up to date", sourceBucket, sourceKey)
+		return nil, nil
+	}
+
+	sourceByKey := make(map[string]s3Types.Object, len(sourceObjects))
+	for _, sourceObject := range sourceObjects {
+		sourceByKey["s3://"+sourceBucket+"/"+*sourceObject.Key] = sourceObject
+	}
+
+	var preparedObjects []types.PreparedObject
+	for _, divergence := range diff.Divergences {
+		if divergence.Kind != types.ExtraObject {
+			preparedObject, err := provider.preparedCopy(ctx, targetProvider, sourceBucket, sourceByKey[divergence.Key], divergence.Target)
+			if err != nil {
+				return nil, err
+			}
+			preparedObjects = append(preparedObjects, preparedObject)
+			continue
+		}
+
+		if !deleteExtra {
+			continue
+		}
+		deleteAction, err := targetProvider.deleteAction(ctx, divergence.Target)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("preparing rm: %s (not in source anymore)", divergence.Target)
+		preparedObjects = append(preparedObjects, types.PreparedObject{
+			Key:    divergence.Target,
+			Size:   divergence.TargetSize,
+			ETag:   divergence.TargetETag,
+			Action: deleteAction,
+		})
+	}
+
+	return preparedObjects, nil
+}
+
+// rate limited deletion of the object behind the full s3 url
+func (provider S3Provider) deleteAction(ctx context.Context, objectURL string) (func() error, error) {
+	bucket, key, err := splitBucketAndKey(objectURL)
+	if err != nil {
+		return nil, err
+	}
+	deleteInput := &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+
+	return provider.limited(ctx, 0, func() error {
+		log.Printf("executing rm: %s", objectURL)
+		return provider.s3Client.delete(ctx, deleteInput)
+	}), nil
+}
+
+// s3 allows at most this many tags per object
+const maxObjectTags = 10
+
+// sets the rendered tags on every object, existing tags are kept unless the options replace them
+func (provider S3Provider) TagPartition(
+	ctx context.Context,
+	partititons types.PartitionList,
+	source types.RuntimeResource,
+	options types.TagOptions,
+) (types.PreparedActions, error) {
+	var (
+		preparedActions types.PreparedActions
+		errs            []error
+	)
+
+	sourceResource, _ := source.(S3Resource) // error case handled at resource creation
+	sourceBucket, sourcePrefix, err := splitBucketAndKey(sourceResource.getPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, partition := range partititons {
+		tags, err := options.Render(source, partition)
+		if err != nil {
+			errs = append(errs, prepareError(source, partition, err))
+			continue
+		}
+		s3Objects, err := provider.listPartitionObjects(ctx, sourceBucket, partitionKey(sourcePrefix, source, partition))
+		if err != nil {
+			errs = append(errs, prepareError(source, partition, err))
+			continue
+		}
+
+		var preparedObjects []types.PreparedObject
+		for _, s3Object := range s3Objects {
+			objectKey := s3Object.Key
+			log.Printf("preparing tag: s3://%s/%s %v", sourceBucket, *objectKey, tags)
+
+			preparedObject := preparedS3Object(sourceBucket, s3Object)
+			preparedObject.Action = func() error {
+				log.Printf("executing tag: s3://%s/%s", sourceBucket, *objectKey)
+				return provider.tagObject(ctx, sourceBucket, objectKey, tags, options)
+			}
+			preparedObjects = append(preparedObjects, preparedObject)
+		}
+
+		preparedActions = append(preparedActions, types.PreparedPartitionAction{
+			Partition: partition,
+			Objects:   preparedObjects,
+		})
+	}
+
+	return preparedActions, errors.Join(errs...)
+}
+
+func (provider S3Provider) tagObject(ctx context.Context, bucket string, key *string, tags map[string]string, options types.TagOptions) error {
+	tagSet := make(map[string]string)
+
+	// replacing needs no knowledge of the current tags
+	if !options.Replace {
+		var current *s3.GetObjectTaggingOutput
+		err := provider.limited(ctx, 0, func() (err error) {
+			current, err = provider.s3Client.getTags(ctx, &s3.GetObjectTaggingInput{Bucket: aws.String(bucket), Key: key})
+			return
+		})()
+		if err != nil {
+			return err
+		}
+		for _, tag := range current.TagSet {
+			tagSet[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+	for tagKey, tagValue := range tags {
+		tagSet[tagKey] = tagValue
+	}
+	for _, tagKey := range options.Remove {
+		delete(tagSet, tagKey)
+	}
+
+	if len(tagSet) > maxObjectTags {
+		return fmt.Errorf("s3://%s/%s would have %d tags (at most %d are allowed)", bucket, *key, len(tagSet), maxObjectTags)
+	}
+
+	tagging := &s3Types.Tagging{TagSet: make([]s3Types.Tag, 0, len(tagSet))}
+	for tagKey, tagValue := range tagSet {
+		tagging.TagSet = append(tagging.TagSet, s3Types.Tag{Key: aws.String(tagKey), Value: aws.String(tagValue)})
+	}
+
+	return provider.limited(ctx, 0, func() error {
+		return provider.s3Client.putTags(ctx, &s3.PutObjectTaggingInput{Bucket: aws.String(bucket), Key: key, Tagging: tagging})
+	})()
+}
@@ -0,0 +1,303 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"smartclip.de/cloud-cleaner/partitions"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+func TestCompareObjects(test *testing.T) {
+	object := func(key string, size int64, etag string) s3Types.Object {
+		return s3Types.Object{Key: aws.String(key), Size: size, ETag: aws.String(etag)}
+	}
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		source   []s3Types.Object
+		target   []s3Types.Object
+		expected []string
+	}{
+		{
+			name:     "identical partitions",
+			source:   []s3Types.Object{object("raw/dt=1/a", 1, "x")},
+			target:   []s3Types.Object{object("archive/dt=1/a", 1, "x")},
+			expected: nil,
+		},
+		{
+			name:     "every kind of divergence",
+			source:   []s3Types.Object{object("raw/dt=1/a", 1, "x"), object("raw/dt=1/b", 1, "x"), object("raw/dt=1/c", 1, "x")},
+			target:   []s3Types.Object{object("archive/dt=1/b", 2, "x"), object("archive/dt=1/c", 1, "y"), object("archive/dt=1/d", 1, "x")},
+			expected: []string{types.MissingObject, types.SizeMismatch, types.ETagMismatch, types.ExtraObject},
+		},
+		{
+			name:     "multipart etags are not compared",
+			source:   []s3Types.Object{object("raw/dt=1/a", 1, "x-2")},
+			target:   []s3Types.Object{object("archive/dt=1/a", 1, "y")},
+			expected: nil,
+		},
+		{
+			name:     "streamed multipart copies are not compared",
+			source:   []s3Types.Object{object("raw/dt=1/a", 1, "x")},
+			target:   []s3Types.Object{object("archive/dt=1/a", 1, "y-3")},
+			expected: nil,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			err := compareObjects("bucket", "raw/dt=1", subtest.source, "bucket", "archive/dt=1", subtest.target)
+
+			// assert
+			var diff *types.DivergenceError
+			if !errors.As(err, &diff) {
+				if subtest.expected != nil {
+					test.Errorf("test %q failed for missing divergence", subtest.name)
+				}
+				return
+			}
+
+			kinds := make(map[string]int)
+			for _, divergence := range diff.Divergences {
+				kinds[divergence.Kind]++
+			}
+			for _, kind := range subtest.expected {
+				if kinds[kind] != 1 {
+					test.Errorf("test %q failed for %d divergences of kind %q", subtest.name, kinds[kind], kind)
+				}
+			}
+			if len(diff.Divergences) != len(subtest.expected) {
+				test.Errorf("test %q failed for %d != %d divergences", subtest.name, len(diff.Divergences), len(subtest.expected))
+			}
+		})
+	}
+}
+
+// answers head requests with "ongoing-request" until the given number of polls passed
+type fakeRestoreClient struct {
+	s3Client
+	restored []string
+	polls    int
+	pending  int
+}
+
+func (client *fakeRestoreClient) restore(ctx context.Context, input *s3.RestoreObjectInput) error {
+	client.restored = append(client.restored, *input.Key)
+	return nil
+}
+
+func (client *fakeRestoreClient) head(ctx context.Context, input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	client.polls++
+	if client.polls <= client.pending {
+		return &s3.HeadObjectOutput{Restore: aws.String(`ongoing-request="true"`)}, nil
+	}
+	return &s3.HeadObjectOutput{Restore: aws.String(`ongoing-request="false", expiry-date="Fri, 23 Dec 2026 00:00:00 GMT"`)}, nil
+}
+
+func TestRestoreAndWait(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name    string
+		pending int
+		timeout time.Duration
+		polls   int
+		aborted bool
+	}{
+		{
+			name:    "already restored",
+			pending: 0,
+			timeout: time.Second,
+			polls:   2,
+		},
+		{
+			name:    "restored after polling",
+			pending: 3,
+			timeout: time.Second,
+			polls:   5,
+		},
+		{
+			name:    "aborted while waiting",
+			pending: 1000,
+			timeout: 20 * time.Millisecond,
+			aborted: true,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), subtest.timeout)
+			defer cancel()
+
+			client := &fakeRestoreClient{pending: subtest.pending}
+			provider := S3Provider{s3Client: client}
+			var objects []types.PreparedObject
+			for _, key := range []string{"raw/dt=1/a", "raw/dt=1/b"} {
+				restoreInput := &s3.RestoreObjectInput{Bucket: aws.String("bucket"), Key: aws.String(key)}
+				objects = append(objects, types.PreparedObject{
+					Key:    "s3://bucket/" + key,
+					Action: func() error { return client.restore(ctx, restoreInput) },
+				})
+			}
+
+			// act
+			err := provider.restoreAndWait(ctx, objects, time.Millisecond)()
+
+			// assert
+			if len(client.restored) != len(objects) {
+				test.Errorf("test %q failed for %d restores requested before polling", subtest.name, len(client.restored))
+			}
+			if subtest.aborted {
+				if err == nil || !strings.Contains(err.Error(), "aborted") {
+					test.Errorf("test %q failed for %v", subtest.name, err)
+				}
+				return
+			}
+			if err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			if client.polls != subtest.polls {
+				test.Errorf("test %q failed for %d != %d polls", subtest.name, client.polls, subtest.polls)
+			}
+		})
+	}
+}
+
+func TestNonS3Target(test *testing.T) {
+	partitionSpec := []interface{}{map[string]interface{}{"name": "dt", "datatype": "date"}}
+	s3Provider := &S3HiveProvider{}
+	source, err := s3Provider.MakeRuntimResource(map[string]interface{}{"name": "raw", "prefix": "s3://bucket/raw/", "partitionspec": partitionSpec})
+	if err != nil {
+		test.Fatal(err)
+	}
+	source.SetProvider(s3Provider)
+	trinoProvider := &TrinoClient{}
+	target, err := trinoProvider.MakeRuntimResource(map[string]interface{}{"name": "table", "table": "hive.raw.events", "partitionspec": partitionSpec})
+	if err != nil {
+		test.Fatal(err)
+	}
+	target.SetProvider(trinoProvider)
+
+	// arrange
+	provider := S3Provider{}
+	testTabel := []struct {
+		name   string
+		action func() (types.PreparedActions, error)
+	}{
+		{
+			name: "copy",
+			action: func() (types.PreparedActions, error) {
+				return provider.CopyPartition(context.Background(), nil, source, target)
+			},
+		},
+		{
+			name: "move",
+			action: func() (types.PreparedActions, error) {
+				return provider.MovePartition(context.Background(), nil, source, target)
+			},
+		},
+		{
+			name: "verify",
+			action: func() (types.PreparedActions, error) {
+				return provider.VerifyPartition(context.Background(), nil, source, target)
+			},
+		},
+		{
+			name: "sync",
+			action: func() (types.PreparedActions, error) {
+				return provider.SyncPartition(context.Background(), nil, source, target, true)
+			},
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			_, err := subtest.action()
+
+			// assert
+			if err == nil || err.Error() != `target "table" is no s3 resource` {
+				test.Errorf("test %q failed for %v", subtest.name, err)
+			}
+		})
+	}
+}
+
+// lists the objects starting with the requested prefix like s3 does
+type fakeListingClient struct {
+	s3Client
+	keys []string
+}
+
+func (client fakeListingClient) listS3(ctx context.Context, input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	output := &s3.ListObjectsV2Output{}
+	for _, key := range client.keys {
+		if strings.HasPrefix(key, *input.Prefix) {
+			output.Contents = append(output.Contents, s3Types.Object{Key: aws.String(key)})
+		}
+	}
+
+	return output, nil
+}
+
+func TestPartitionKeyPrefixes(test *testing.T) {
+	partitionSpec := []interface{}{map[string]interface{}{"name": "hour", "datatype": "int"}}
+	source, err := (&S3HiveProvider{}).MakeRuntimResource(map[string]interface{}{"name": "raw", "prefix": "s3://bucket/raw", "partitionspec": partitionSpec})
+	if err != nil {
+		test.Fatal(err)
+	}
+	provider := S3Provider{
+		BaseProvider: BaseProvider{listingRetries: &atomic.Int64{}},
+		s3Client:     fakeListingClient{keys: []string{"raw/hour=1/a", "raw/hour=10/b", "raw/hour=19/c"}},
+	}
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{
+			name:     "value prefixing other values",
+			value:    "1",
+			expected: []string{"s3://bucket/raw/hour=1/a"},
+		},
+		{
+			name:     "value prefixed by other value",
+			value:    "10",
+			expected: []string{"s3://bucket/raw/hour=10/b"},
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			partition := &HivePartition{BasePartition: partitions.BasePartition{PartitionValues: []string{subtest.value}}}
+
+			// act
+			preparedActions, err := provider.RemovePartition(context.Background(), types.PartitionList{partition}, source)
+
+			// assert
+			if err != nil {
+				test.Fatalf("test %q failed for %s", subtest.name, err)
+			}
+			var keys []string
+			for _, object := range preparedActions[0].Objects {
+				keys = append(keys, object.Key)
+			}
+			if !reflect.DeepEqual(keys, subtest.expected) {
+				test.Errorf("test %q failed for %v != %v", subtest.name, keys, subtest.expected)
+			}
+		})
+	}
+}
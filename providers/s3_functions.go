@@ -1,14 +1,17 @@
 package providers
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
-	"sync"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 
 	"smartclip.de/cloud-cleaner/partitions"
+	"smartclip.de/cloud-cleaner/types"
 )
 
 func splitBucketAndKey(prefix string) (bucket string, key string, err error) {
@@ -33,6 +36,60 @@ func splitBucketAndKey(prefix string) (bucket string, key string, err error) {
 	return
 }
 
+// key prefix of a single partition below the resource prefix (hive style column=value path elements)
+// the trailing slash keeps e.g. "hour=1" from also listing the objects of "hour=10"
+func partitionKey(resourcePrefix string, resource types.RuntimeResource, partition types.Partition) string {
+	partitionSpec := resource.GetPartitionSpec()
+	partitionValues := partition.GetValues()
+
+	partitionKeys := make([]string, len(partitionSpec))
+	for idx, spec := range partitionSpec {
+		partitionKeys[idx] = spec.Name + "=" + partitionValues[idx]
+	}
+
+	return resourcePrefix + "/" + strings.Join(partitionKeys, "/") + "/"
+}
+
+// lists all objects below the key by following the continuation tokens of the s3 api
+func (provider S3Provider) listPartitionObjects(ctx context.Context, bucket string, key string) ([]s3Types.Object, error) {
+	var s3Objects []s3Types.Object
+
+	listPrefix := s3.ListObjectsV2Input{
+		Bucket:  aws.String(bucket),
+		Prefix:  aws.String(key),
+		MaxKeys: 100, // TODO: make configurable
+	}
+	listObjectOutput := &s3.ListObjectsV2Output{IsTruncated: true}
+	for listObjectOutput.IsTruncated {
+		var err error
+		if listObjectOutput, err = provider.listWithRetry(ctx, &listPrefix); err != nil {
+			return nil, err
+		}
+		listPrefix.ContinuationToken = listObjectOutput.NextContinuationToken
+
+		s3Objects = append(s3Objects, listObjectOutput.Contents...)
+	}
+
+	return s3Objects, nil
+}
+
+func prepareError(resource types.RuntimeResource, partition types.Partition, err error) error {
+	return &types.ContextError{
+		Stage:     "prepare",
+		Resource:  resource.GetResourceName(),
+		Partition: partition.GetParsedValues().ToString(),
+		Err:       err,
+	}
+}
+
+func preparedS3Object(bucket string, s3Object s3Types.Object) types.PreparedObject {
+	return types.PreparedObject{
+		Key:  "s3://" + bucket + "/" + *s3Object.Key,
+		Size: s3Object.Size,
+		ETag: aws.ToString(s3Object.ETag),
+	}
+}
+
 func hivePartitioning(resource *s3HiveRuntimeResource, s3Object *s3Types.Object, latestPartition *string, errChan chan<- error) {
 	partitionSpecIdx := 0
 	partitionSpec := resource.GetPartitionSpec()
@@ -46,7 +103,6 @@ func hivePartitioning(resource *s3HiveRuntimeResource, s3Object *s3Types.Object,
 		BasePartition: partitions.BasePartition{
 			Resource:        resource,
 			PartitionValues: make([]string, len(partitionSpec)),
-			CompletionWg:    &sync.WaitGroup{},
 		},
 	}
 
@@ -88,7 +144,7 @@ func keysPartitioning(resource *s3KeyRuntimeResource, s3Object *s3Types.Object,
 
 	allMatches := regex.FindStringSubmatch(*s3Object.Key)
 	matches := allMatches[1:] // expected to always have full string in first entry and subgroubs after
-	if len(matches) != len(resource.Partitions) {
+	if len(matches) != len(resource.PartitionSpec) {
 		errorChannel <- fmt.Errorf("mismatching regex captcher group count with partition spec column count for resource %q", resource.Name)
 	}
 	for idx, capture := range matches {
@@ -101,10 +157,10 @@ func keysPartitioning(resource *s3KeyRuntimeResource, s3Object *s3Types.Object,
 		BasePartition: partitions.BasePartition{
 			PartitionValues: matches,
 			Resource:        resource,
-			CompletionWg:    &sync.WaitGroup{},
 		},
-		Size: s3Object.Size,
-		ts:   *s3Object.LastModified,
+		ObjectCount: 1,
+		Size:        s3Object.Size,
+		ts:          *s3Object.LastModified,
 	}
 
 	parsedValues, err := partitions.ParsePartitionString(resource.PartitionSpec, newPartition.PartitionValues)
@@ -0,0 +1,354 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+func TestSplitBucketAndKey(test *testing.T) {
+	type expected struct {
+		bucket string
+		key    string
+	}
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		prefix   string
+		expected expected
+		err      bool
+	}{
+		{
+			name:     "no prefix partition",
+			prefix:   "s3://bucket/key/hallo/welt",
+			expected: expected{bucket: "bucket", key: "key/hallo/welt"},
+		},
+		{
+			name:     "bucket only",
+			prefix:   "s3://bucket",
+			expected: expected{bucket: "bucket", key: ""},
+		},
+		{
+			name:   "no s3 protocol error",
+			prefix: "bucket/key/hallo/welt",
+			err:    true,
+		},
+		{
+			name:     "empty string after protocol",
+			prefix:   "s3://",
+			expected: expected{bucket: "", key: ""},
+			err:      true,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			bucket, key, err := splitBucketAndKey(subtest.prefix)
+
+			// assert
+			if (err == nil) == subtest.err {
+				test.Errorf("%q failed with unexpected error %q", subtest.name, err)
+			}
+
+			if bucket != subtest.expected.bucket || key != subtest.expected.key {
+				test.Errorf(
+					"%q failed with %v != %v",
+					subtest.name,
+					expected{bucket: bucket, key: key},
+					subtest.expected,
+				)
+			}
+		})
+	}
+}
+
+// partition values, object count and size of a collected partition
+type collectedPartition struct {
+	values      []string
+	objectCount int64
+	size        int64
+}
+
+func collectedPartitions(resource types.RuntimeResource) []collectedPartition {
+	var collected []collectedPartition
+	for _, partition := range resource.GetPartitions() {
+		objectCount, _ := partition.GetObjectCount()
+		size, _ := partition.GetSize()
+		collected = append(collected, collectedPartition{values: partition.GetValues(), objectCount: objectCount, size: size})
+	}
+
+	return collected
+}
+
+func s3Resource(provider types.PartitionProvider, conf map[string]interface{}) types.RuntimeResource {
+	resource, err := provider.MakeRuntimResource(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	return resource
+}
+
+func partitionSpec(columns ...string) []interface{} {
+	spec := make([]interface{}, 0, len(columns)/2)
+	for idx := 0; idx < len(columns); idx += 2 {
+		spec = append(spec, map[string]interface{}{"name": columns[idx], "datatype": columns[idx+1]})
+	}
+
+	return spec
+}
+
+func TestHivePartitioning(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name     string
+		object   s3Types.Object
+		spec     []interface{}
+		expected []collectedPartition
+		err      bool
+	}{
+		{
+			name:     "happy path",
+			object:   s3Types.Object{Key: aws.String("abc=123"), LastModified: &time.Time{}},
+			spec:     partitionSpec("abc", "int"),
+			expected: []collectedPartition{{values: []string{"123"}, objectCount: 1}},
+		},
+		{
+			name:     "object size",
+			object:   s3Types.Object{Key: aws.String("abc=123"), Size: 2, LastModified: &time.Time{}},
+			spec:     partitionSpec("abc", "int"),
+			expected: []collectedPartition{{values: []string{"123"}, objectCount: 1, size: 2}},
+		},
+		{
+			name:     "longer prefix partition",
+			object:   s3Types.Object{Key: aws.String("hallo/world/abc=123"), LastModified: &time.Time{}},
+			spec:     partitionSpec("abc", "int"),
+			expected: []collectedPartition{{values: []string{"123"}, objectCount: 1}},
+		},
+		{
+			name:     "multiple equals in hive partition string",
+			object:   s3Types.Object{Key: aws.String("hallo/world/abc=123=test"), LastModified: &time.Time{}},
+			spec:     partitionSpec("abc", "string"),
+			expected: []collectedPartition{{values: []string{"123=test"}, objectCount: 1}},
+		},
+		{
+			name:   "no partition error",
+			object: s3Types.Object{Key: aws.String("hallo/world/abc"), LastModified: &time.Time{}},
+			spec:   partitionSpec("abc", "int"),
+			err:    true,
+		},
+		{
+			name:   "not matching partition key",
+			object: s3Types.Object{Key: aws.String("xyz=123"), LastModified: &time.Time{}},
+			spec:   partitionSpec("abc", "int"),
+			// the partition is still collected, the error fails the collection
+			expected: []collectedPartition{{values: []string{"123"}, objectCount: 1}},
+			err:      true,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			resource := s3Resource(&S3HiveProvider{}, map[string]interface{}{"name": "raw", "prefix": "s3://bucket", "partitionspec": subtest.spec})
+			errorChan := make(chan error, 10)
+
+			// act
+			var latestPartition string
+			hivePartitioning(resource.(*s3HiveRuntimeResource), &subtest.object, &latestPartition, errorChan)
+
+			// assert
+			if (len(errorChan) > 0) != subtest.err {
+				test.Errorf("%q failed with %d unexpected errors", subtest.name, len(errorChan))
+			}
+			if collected := collectedPartitions(resource); !reflect.DeepEqual(collected, subtest.expected) {
+				test.Errorf("%q failed with\nactual: %#v\nexpected: %#v", subtest.name, collected, subtest.expected)
+			}
+		})
+	}
+}
+
+func TestKeysPartitioning(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name     string
+		object   s3Types.Object
+		spec     []interface{}
+		regex    string
+		existing []string
+		expected []collectedPartition
+		err      bool
+	}{
+		{
+			name:     "happy path",
+			object:   s3Types.Object{Key: aws.String("abc/test_1.txt"), LastModified: &time.Time{}},
+			spec:     partitionSpec("a", "string"),
+			regex:    `.+/test_(\d+).txt`,
+			expected: []collectedPartition{{values: []string{"1"}, objectCount: 1}},
+		},
+		{
+			name:   "single matching group but 2 columns ",
+			object: s3Types.Object{Key: aws.String("abc/test_1.txt"), LastModified: &time.Time{}},
+			spec:   partitionSpec("a", "int", "b", "int"),
+			regex:  `.+/test_(\d+).txt`,
+			err:    true,
+		},
+		{
+			name:   "capture group empty match",
+			object: s3Types.Object{Key: aws.String("abc/test_1.txt"), LastModified: &time.Time{}},
+			spec:   partitionSpec("a", "int"),
+			regex:  `.+/test_\d+(.*).txt`,
+			err:    true,
+		},
+		{
+			name:     "duplicate partition key",
+			object:   s3Types.Object{Key: aws.String("abc/test_1.txt"), LastModified: &time.Time{}},
+			spec:     partitionSpec("a", "int"),
+			regex:    `.+/test_(\d+).txt`,
+			existing: []string{"abc/test_1.txt"},
+			expected: []collectedPartition{{values: []string{"1"}, objectCount: 1}},
+			err:      true,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			resource := s3Resource(&S3KeyProvider{}, map[string]interface{}{"name": "raw", "prefix": "s3://bucket", "regex": subtest.regex, "partitionspec": subtest.spec})
+			var latestPartition string
+			for _, key := range subtest.existing {
+				keysPartitioning(resource.(*s3KeyRuntimeResource), &s3Types.Object{Key: aws.String(key), LastModified: &time.Time{}}, &latestPartition, make(chan error, 10))
+			}
+			errorChan := make(chan error, 10)
+
+			// act
+			keysPartitioning(resource.(*s3KeyRuntimeResource), &subtest.object, &latestPartition, errorChan)
+
+			// assert
+			if (len(errorChan) > 0) != subtest.err {
+				test.Errorf("%q failed with %d unexpected errors", subtest.name, len(errorChan))
+			}
+			if collected := collectedPartitions(resource); !reflect.DeepEqual(collected, subtest.expected) {
+				test.Errorf("%q failed with\nactual: %#v\nexpected: %#v", subtest.name, collected, subtest.expected)
+			}
+		})
+	}
+}
+
+// returns the listing chunks one after another
+type fakeChunkClient struct {
+	s3Client
+	chunks []s3.ListObjectsV2Output
+	err    error
+}
+
+func (client *fakeChunkClient) listS3(ctx context.Context, input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	if client.err != nil {
+		return nil, client.err
+	}
+	chunk := client.chunks[0]
+	client.chunks = client.chunks[1:]
+
+	return &chunk, nil
+}
+
+func TestCollectS3Partitions(test *testing.T) {
+	object := func(key string) s3Types.Object {
+		return s3Types.Object{Key: aws.String(key), LastModified: &time.Time{}}
+	}
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		client   *fakeChunkClient
+		provider types.PartitionProvider
+		conf     map[string]interface{}
+		expected []collectedPartition
+		err      bool
+	}{
+		{
+			name: "multiple chunks",
+			client: &fakeChunkClient{chunks: []s3.ListObjectsV2Output{
+				{IsTruncated: true, Contents: []s3Types.Object{object("abc=123")}},
+				{Contents: []s3Types.Object{object("abc=123")}},
+			}},
+			provider: &S3HiveProvider{},
+			conf:     map[string]interface{}{"prefix": "s3://abc", "partitionspec": partitionSpec("abc", "int")},
+			expected: []collectedPartition{{values: []string{"123"}, objectCount: 2}},
+		},
+		{
+			name:     "happy path",
+			client:   &fakeChunkClient{chunks: []s3.ListObjectsV2Output{{Contents: []s3Types.Object{object("abc=123")}}}},
+			provider: &S3HiveProvider{},
+			conf:     map[string]interface{}{"prefix": "s3://abc", "partitionspec": partitionSpec("abc", "int")},
+			expected: []collectedPartition{{values: []string{"123"}, objectCount: 1}},
+		},
+		{
+			name:     "no s3 prefix",
+			client:   &fakeChunkClient{},
+			provider: &S3HiveProvider{},
+			conf:     map[string]interface{}{"prefix": "s3a://abc", "partitionspec": partitionSpec("abc", "int")},
+			err:      true,
+		},
+		{
+			name:     "listing error",
+			client:   &fakeChunkClient{err: fmt.Errorf("listing failed")},
+			provider: &S3HiveProvider{},
+			conf:     map[string]interface{}{"prefix": "s3://abc", "partitionspec": partitionSpec("abc", "int")},
+			err:      true,
+		},
+		{
+			name:     "listing returned no objects",
+			client:   &fakeChunkClient{chunks: []s3.ListObjectsV2Output{{}}},
+			provider: &S3HiveProvider{},
+			conf:     map[string]interface{}{"prefix": "s3://abc", "partitionspec": partitionSpec("abc", "int")},
+			err:      true,
+		},
+		{
+			name:     "contains delta log",
+			client:   &fakeChunkClient{chunks: []s3.ListObjectsV2Output{{Contents: []s3Types.Object{object("_delta_log/")}}}},
+			provider: &S3HiveProvider{},
+			conf:     map[string]interface{}{"prefix": "s3://abc", "partitionspec": partitionSpec("abc", "int")},
+			err:      true,
+		},
+		{
+			name:     "regex provider",
+			client:   &fakeChunkClient{chunks: []s3.ListObjectsV2Output{{Contents: []s3Types.Object{object("test_1.txt")}}}},
+			provider: &S3KeyProvider{},
+			conf:     map[string]interface{}{"prefix": "s3://abc", "regex": `(\d+).txt$`, "partitionspec": partitionSpec("number", "int")},
+			expected: []collectedPartition{{values: []string{"1"}, objectCount: 1}},
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			subtest.conf["name"] = "raw"
+			resource := s3Resource(subtest.provider, subtest.conf)
+			provider := S3Provider{
+				BaseProvider: BaseProvider{RetryPolicy: types.RetryPolicy{MaxAttempts: 1}, listingRetries: &atomic.Int64{}},
+				s3Client:     subtest.client,
+			}
+			errorChan := make(chan error, 10)
+
+			// act
+			err := provider.collectResourcePartitions(context.Background(), resource.(S3Resource), errorChan)
+
+			// assert
+			if (err != nil || len(errorChan) > 0) != subtest.err {
+				test.Errorf("%q failed with unexpected error %v (%d partition errors)", subtest.name, err, len(errorChan))
+			}
+			if collected := collectedPartitions(resource); !reflect.DeepEqual(collected, subtest.expected) {
+				test.Errorf("%q failed with\nactual: %#v\nexpected: %#v", subtest.name, collected, subtest.expected)
+			}
+		})
+	}
+}
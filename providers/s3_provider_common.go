@@ -10,26 +10,88 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"smartclip.de/cloud-cleaner/types"
 )
 
-func newS3Provider(conf map[string]interface{}) (provider S3Provider, err error) {
+// default part size of streamed copies between providers
+const defaultPartSize = 64 << 20
+
+func newS3Provider(ctx context.Context, conf map[string]interface{}) (provider S3Provider, err error) {
 	// configure via envs 'AWS_ACCESS_KEY_ID', 'AWS_SECRET_ACCESS_KEY' and 'AWS_DEFAULT_REGION'
+	// or for additional accounts and endpoints via "profile", "region" and "endpoint"
 
 	if provider.BaseProvider, err = MakeBaseProvider(conf); err != nil {
 		return
 	}
 
-	clientConfig, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
+	if provider.requestLimiter, err = makeRateLimiter(conf, "requestspersecond", provider.Name); err != nil {
+		return
+	}
+	// only copies transfer data, the byte limit is ignored by all other requests
+	if provider.byteLimiter, err = makeRateLimiter(conf, "bytespersecond", provider.Name); err != nil {
+		return
+	}
+
+	var loadOptions []func(*config.LoadOptions) error
+	for field, option := range map[string]func(string) config.LoadOptionsFunc{
+		"profile": config.WithSharedConfigProfile,
+		"region":  config.WithRegion,
+	} {
+		if val, ok := conf[field]; ok {
+			str, ok := val.(string)
+			if !ok || str == "" {
+				return S3Provider{}, fmt.Errorf("%s of provider %q is not a string", field, provider.Name)
+			}
+			loadOptions = append(loadOptions, option(str))
+		}
+	}
+
+	var pathStyle bool
+	if val, ok := conf["endpoint"]; ok {
+		if provider.endpoint, ok = val.(string); !ok || provider.endpoint == "" {
+			return S3Provider{}, fmt.Errorf("endpoint of provider %q is not a string", provider.Name)
+		}
+		// most s3 compatible stores (e.g. minio) only support path style addressing
+		pathStyle = true
+	}
+	if val, ok := conf["pathstyle"]; ok {
+		if pathStyle, ok = val.(bool); !ok {
+			return S3Provider{}, fmt.Errorf("pathstyle of provider %q is not a boolean", provider.Name)
+		}
+	}
+
+	provider.partSize = defaultPartSize
+	if val, ok := conf["partsize"]; ok {
+		partSize, ok := types.ConfigInt(val)
+		// s3 requires at least 5 MiB for every but the last part
+		if !ok || partSize < 5<<20 {
+			return S3Provider{}, fmt.Errorf("partsize of provider %q is not an integer of at least 5 MiB", provider.Name)
+		}
+		provider.partSize = int64(partSize)
+	}
+
+	if provider.awsConfig, err = config.LoadDefaultConfig(ctx, loadOptions...); err != nil {
 		return S3Provider{}, err
 	}
-	provider.s3Client = s3ListingClient{s3.NewFromConfig(clientConfig)}
+	provider.s3Client = s3ListingClient{newS3Client(provider.awsConfig, provider.endpoint, pathStyle)}
 
 	return
 }
 
-func (provider *S3Provider) CheckAccess(errChan chan<- error, wg *sync.WaitGroup) {
-	_, err := provider.s3Client.buckets()
+func newS3Client(awsConfig aws.Config, endpoint string, pathStyle bool) *s3.Client {
+	return s3.NewFromConfig(awsConfig, func(options *s3.Options) {
+		if endpoint != "" {
+			options.EndpointResolver = s3.EndpointResolverFromURL(endpoint)
+		}
+		options.UsePathStyle = pathStyle
+		// the configured retry policy is the only one, sdk retries would multiply its attempts unnoticed
+		options.Retryer = aws.NopRetryer{}
+	})
+}
+
+func (provider *S3Provider) CheckAccess(ctx context.Context, errChan chan<- error, wg *sync.WaitGroup) {
+	_, err := provider.s3Client.buckets(ctx)
 	if err != nil {
 		errChan <- err
 	}
@@ -37,77 +99,114 @@ func (provider *S3Provider) CheckAccess(errChan chan<- error, wg *sync.WaitGroup
 	wg.Done()
 }
 
-func (provider S3Provider) CollectPartitions(errorChannel chan<- error, wg *sync.WaitGroup) {
+// every resource is collected on its own so one failing resource does not stop the others
+func (provider S3Provider) CollectPartitions(ctx context.Context, errorChannel chan<- error, wg *sync.WaitGroup) {
 	for resourceTmp := range provider.InputChan {
 		resource := resourceTmp.(S3Resource)
 
-		bucket, prefix, err := splitBucketAndKey(resource.getPrefix())
-		if err != nil {
-			errorChannel <- err
-			break
+		if err := provider.collectResourcePartitions(ctx, resource, errorChannel); err != nil {
+			errorChannel <- &types.ContextError{Stage: "collect", Resource: resource.GetResourceName(), Err: err}
+		}
+		wg.Done()
+	}
+}
+
+func (provider S3Provider) collectResourcePartitions(ctx context.Context, resource S3Resource, errorChannel chan<- error) error {
+	bucket, prefix, err := splitBucketAndKey(resource.getPrefix())
+	if err != nil {
+		return err
+	}
+
+	var latestPartitionKey string
+	listingChunckNumber := 1
+	s3ObjectFilter := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(bucket),
+		Prefix:  aws.String(prefix),
+		MaxKeys: 100, // TODO make available from config
+	}
+	listObjectOutput := &s3.ListObjectsV2Output{IsTruncated: true}
+
+	// s3 objects are loaded in chunks -> iterate over all chunks
+	log.Printf("start s3 partition collection for %q", resource.GetResourceName())
+	for listObjectOutput.IsTruncated {
+		if listObjectOutput, err = provider.listWithRetry(ctx, s3ObjectFilter); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("partition collection aborted: %w", ctx.Err())
+			}
+			log.Printf("s3 listing error... your s3 prefix may not exist (%q)", "s3://"+bucket+"/"+prefix)
+			return err
 		}
 
-		var latestPartitionKey string
-		listingChunckNumber := 1
-		s3ObjectFilter := &s3.ListObjectsV2Input{
-			Bucket:  aws.String(bucket),
-			Prefix:  aws.String(prefix),
-			MaxKeys: 100, // TODO make available from config
+		if len(listObjectOutput.Contents) < 1 {
+			return fmt.Errorf("s3 listing of %q returned no objects", "s3://"+bucket+"/"+prefix)
 		}
-		listObjectOutput := &s3.ListObjectsV2Output{IsTruncated: true}
-
-		// s3 objects are loaded in chunks -> iterate over all chunks
-		log.Printf("start s3 partition collection for %q", resource.GetResourceName())
-		for listObjectOutput.IsTruncated {
-			if listObjectOutput, err = provider.s3Client.listS3(s3ObjectFilter); err != nil {
-				log.Printf("s3 listing error... your s3 prefix may not exist (%q)", "s3://"+bucket+"/"+prefix)
-				errorChannel <- err
-				return
+
+		// only keep needed prefix information and append to list
+		for _, s3Object := range listObjectOutput.Contents {
+			if strings.Contains(*s3Object.Key, "2023-06") {
+				listingChunckNumber++
 			}
 
-			if len(listObjectOutput.Contents) < 1 {
-				errorChannel <- fmt.Errorf("s3 listing of %q returned no objects", "s3://"+bucket+"/"+prefix)
-				return
+			// TODO: this likely only need to be checked for the first element?
+			if strings.Contains(*s3Object.Key, "_delta_log/") {
+				return fmt.Errorf("the key %q, indicates a delta lake table (avoid managing yourself)", *s3Object.Key)
 			}
 
-			// only keep needed prefix information and append to list
-			for _, s3Object := range listObjectOutput.Contents {
-				if strings.Contains(*s3Object.Key, "2023-06") {
-					listingChunckNumber++
-				}
-
-				// TODO: this likely only need to be checked for the first element?
-				if strings.Contains(*s3Object.Key, "_delta_log/") {
-					errorChannel <- fmt.Errorf("the key %q, indicates a delta lake table (avoid managing yourself)", *s3Object.Key)
-					return
-				}
-
-				switch r := resource.(type) {
-				case *s3HiveRuntimeResource:
-					hivePartitioning(r, &s3Object, &latestPartitionKey, errorChannel)
-				case *s3KeyRuntimeResource:
-					keysPartitioning(r, &s3Object, &latestPartitionKey, errorChannel)
-				default:
-					errorChannel <- fmt.Errorf("s3 resource type %q unknown", resource.GetResourceName())
-					return
-				}
+			switch r := resource.(type) {
+			case *s3HiveRuntimeResource:
+				hivePartitioning(r, &s3Object, &latestPartitionKey, errorChannel)
+			case *s3KeyRuntimeResource:
+				keysPartitioning(r, &s3Object, &latestPartitionKey, errorChannel)
+			default:
+				return fmt.Errorf("s3 resource type %q unknown", resource.GetResourceName())
 			}
+		}
 
-			// log some debugging information
-			log.Printf(
-				"chunk %d of resource %q found %d partitions so far",
-				listingChunckNumber,
-				resource.GetResourceName(),
-				len(resource.GetPartitions()),
-			)
+		// log some debugging information
+		log.Printf(
+			"chunk %d of resource %q found %d partitions so far",
+			listingChunckNumber,
+			resource.GetResourceName(),
+			len(resource.GetPartitions()),
+		)
 
-			// update ContinuationToken so next chunk will be loaded
-			s3ObjectFilter.ContinuationToken = listObjectOutput.NextContinuationToken
+		// update ContinuationToken so next chunk will be loaded
+		s3ObjectFilter.ContinuationToken = listObjectOutput.NextContinuationToken
 
-			listingChunckNumber++
+		listingChunckNumber++
+	}
+
+	log.Printf("finished s3 partition collection for %q", resource.GetResourceName())
+	return nil
+}
+
+// listing is read only and therefore always safe to retry
+func (provider S3Provider) listWithRetry(ctx context.Context, input *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	var output *s3.ListObjectsV2Output
+	retries, err := provider.RetryPolicy.Do(ctx, func() (err error) {
+		if err = provider.requestLimiter.Wait(ctx, 1); err != nil {
+			return
 		}
+		output, err = provider.s3Client.listS3(ctx, input)
+		return
+	})
+	provider.listingRetries.Add(int64(retries))
 
-		log.Printf("finished s3 partition collection for %q", resource.GetResourceName())
-		wg.Done()
+	return output, err
+}
+
+// every attempt of an action waits for the rate limits of the provider first
+func (provider S3Provider) limited(ctx context.Context, bytes int64, action func() error) func() error {
+	return func() error {
+		if err := provider.requestLimiter.Wait(ctx, 1); err != nil {
+			return err
+		}
+		if bytes > 0 {
+			if err := provider.byteLimiter.Wait(ctx, float64(bytes)); err != nil {
+				return err
+			}
+		}
+
+		return action()
 	}
 }
@@ -0,0 +1,110 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+func TestListWithRetryAttempts(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name        string
+		maxAttempts int
+	}{
+		{
+			name:        "single attempt",
+			maxAttempts: 1,
+		},
+		{
+			name:        "configured attempts",
+			maxAttempts: 4,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			var requests atomic.Int64
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				requests.Add(1)
+				writer.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			awsConfig := aws.Config{Region: "eu-central-1", Credentials: aws.AnonymousCredentials{}}
+			provider := S3Provider{
+				BaseProvider: BaseProvider{
+					RetryPolicy:    types.RetryPolicy{MaxAttempts: subtest.maxAttempts, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, RetryOn: []string{types.ServerErrors}},
+					listingRetries: &atomic.Int64{},
+				},
+				s3Client: s3ListingClient{newS3Client(awsConfig, server.URL, true)},
+			}
+
+			// act
+			_, err := provider.listWithRetry(context.Background(), &s3.ListObjectsV2Input{Bucket: aws.String("bucket")})
+
+			// assert
+			if err == nil {
+				test.Errorf("test %q failed for missing error", subtest.name)
+			}
+			if requests.Load() != int64(subtest.maxAttempts) {
+				test.Errorf("test %q failed for %d != %d requests", subtest.name, requests.Load(), subtest.maxAttempts)
+			}
+			if provider.GetListingRetries() != int64(subtest.maxAttempts-1) {
+				test.Errorf("test %q failed for %d != %d listing retries", subtest.name, provider.GetListingRetries(), subtest.maxAttempts-1)
+			}
+		})
+	}
+}
+
+func TestS3ProviderInit(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name     string
+		provider types.PartitionProvider
+		kind     string
+	}{
+		{
+			name:     "init hive provider",
+			provider: &S3HiveProvider{},
+			kind:     S3HiveProviderType,
+		},
+		{
+			name:     "init key provider",
+			provider: &S3KeyProvider{},
+			kind:     S3KeyProviderType,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			errChan := make(chan error, 1)
+			wg.Add(1)
+
+			// act
+			subtest.provider.Init(context.Background(), map[string]interface{}{"name": "lake", "kind": subtest.kind}, errChan, &wg)
+			wg.Wait()
+
+			// assert
+			if len(errChan) > 0 {
+				test.Errorf("%q failed with error %q", subtest.name, <-errChan)
+			}
+			if subtest.provider.GetProviderType() != types.ProviderType(subtest.kind) {
+				test.Errorf("%q failed with %q != %q", subtest.name, subtest.provider.GetProviderType(), subtest.kind)
+			}
+			if subtest.provider.ResourceInputChan() == nil {
+				test.Errorf("%q failed without resource channel", subtest.name)
+			}
+		})
+	}
+}
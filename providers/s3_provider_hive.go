@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -30,8 +31,16 @@ func (partition *HivePartition) GetTimestamp() (time.Time, error) {
 	return partition.LatestTs, nil
 }
 
-func (provider *S3HiveProvider) Init(conf map[string]interface{}, errChan chan<- error, wg *sync.WaitGroup) {
-	s3Provider, err := newS3Provider(conf)
+func (partition *HivePartition) GetSize() (int64, error) {
+	return partition.Size, nil
+}
+
+func (partition *HivePartition) GetObjectCount() (int64, error) {
+	return int64(partition.ObjectCount), nil
+}
+
+func (provider *S3HiveProvider) Init(ctx context.Context, conf map[string]interface{}, errChan chan<- error, wg *sync.WaitGroup) {
+	s3Provider, err := newS3Provider(ctx, conf)
 	if err != nil {
 		errChan <- err
 	}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -22,16 +23,25 @@ type s3KeyRuntimeResource struct {
 
 type KeyPartition struct {
 	partitions.BasePartition
-	Size int64
-	ts   time.Time
+	ObjectCount uint
+	Size        int64
+	ts          time.Time
 }
 
 func (partition *KeyPartition) GetTimestamp() (time.Time, error) {
 	return partition.ts, nil
 }
 
-func (provider *S3KeyProvider) Init(conf map[string]interface{}, errChan chan<- error, wg *sync.WaitGroup) {
-	s3Provider, err := newS3Provider(conf)
+func (partition *KeyPartition) GetSize() (int64, error) {
+	return partition.Size, nil
+}
+
+func (partition *KeyPartition) GetObjectCount() (int64, error) {
+	return int64(partition.ObjectCount), nil
+}
+
+func (provider *S3KeyProvider) Init(ctx context.Context, conf map[string]interface{}, errChan chan<- error, wg *sync.WaitGroup) {
+	s3Provider, err := newS3Provider(ctx, conf)
 	if err != nil {
 		errChan <- err
 	}
@@ -76,6 +86,7 @@ func (currentPartition *KeyPartition) UpdatePartition(updatePartition types.Part
 		return fmt.Errorf("partition has incorrect type for update")
 	}
 
+	currentPartition.ObjectCount += otherPartition.ObjectCount
 	currentPartition.Size += otherPartition.Size
 
 	if currentPartition.ts.After(otherPartition.ts) {
@@ -0,0 +1,177 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"smartclip.de/cloud-cleaner/types"
+)
+
+// promoted to every s3 provider kind so the s3 side of another resource can be reached
+func (provider S3Provider) getS3Provider() S3Provider {
+	return provider
+}
+
+// the provider of the target resource which may differ from the source provider (e.g. a backup account)
+func targetS3Provider(target types.RuntimeResource) (S3Provider, error) {
+	targetProvider, ok := target.GetProvider().(interface{ getS3Provider() S3Provider })
+	if !ok {
+		return S3Provider{}, fmt.Errorf("target %q is no s3 resource", target.GetResourceName())
+	}
+
+	return targetProvider.getS3Provider(), nil
+}
+
+// server side copies need one set of credentials reaching source and target on the same endpoint
+func (provider S3Provider) sharesCredentials(ctx context.Context, other S3Provider) bool {
+	if provider.Name == other.Name {
+		return true
+	}
+	if provider.awsConfig.Region != other.awsConfig.Region || provider.endpoint != other.endpoint {
+		return false
+	}
+	if provider.awsConfig.Credentials == nil || other.awsConfig.Credentials == nil {
+		return false
+	}
+
+	own, err := provider.awsConfig.Credentials.Retrieve(ctx)
+	if err != nil {
+		return false
+	}
+	others, err := other.awsConfig.Credentials.Retrieve(ctx)
+	if err != nil {
+		return false
+	}
+
+	return own.AccessKeyID == others.AccessKeyID
+}
+
+// downloads the object from this provider and uploads it with the client of the target provider
+// objects larger than the part size of the target are transferred part by part
+func (provider S3Provider) streamCopy(
+	ctx context.Context,
+	targetProvider S3Provider,
+	sourceBucket string,
+	sourceKey string,
+	size int64,
+	targetBucket string,
+	targetKey string,
+) error {
+	if size <= targetProvider.partSize {
+		body, err := provider.readRange(ctx, sourceBucket, sourceKey, 0, size)
+		if err != nil {
+			return err
+		}
+
+		return targetProvider.limited(ctx, size, func() error {
+			return targetProvider.s3Client.put(ctx, &s3.PutObjectInput{
+				Bucket:        aws.String(targetBucket),
+				Key:           aws.String(targetKey),
+				Body:          bytes.NewReader(body),
+				ContentLength: size,
+			})
+		})()
+	}
+
+	var upload *s3.CreateMultipartUploadOutput
+	err := targetProvider.limited(ctx, 0, func() (err error) {
+		upload, err = targetProvider.s3Client.createMultipart(ctx, &s3.CreateMultipartUploadInput{
+			Bucket: aws.String(targetBucket),
+			Key:    aws.String(targetKey),
+		})
+		return
+	})()
+	if err != nil {
+		return err
+	}
+
+	parts, err := provider.uploadParts(ctx, targetProvider, sourceBucket, sourceKey, size, upload)
+	if err != nil {
+		// incomplete uploads are billed until aborted, even if the run got cancelled
+		abortErr := targetProvider.s3Client.abortMultipart(context.Background(), &s3.AbortMultipartUploadInput{
+			Bucket:   upload.Bucket,
+			Key:      upload.Key,
+			UploadId: upload.UploadId,
+		})
+		if abortErr != nil {
+			log.Printf("could not abort multipart upload of s3://%s/%s: %s", targetBucket, targetKey, abortErr)
+		}
+		return err
+	}
+
+	return targetProvider.limited(ctx, 0, func() error {
+		return targetProvider.s3Client.completeMultipart(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          upload.Bucket,
+			Key:             upload.Key,
+			UploadId:        upload.UploadId,
+			MultipartUpload: &s3Types.CompletedMultipartUpload{Parts: parts},
+		})
+	})()
+}
+
+func (provider S3Provider) uploadParts(
+	ctx context.Context,
+	targetProvider S3Provider,
+	sourceBucket string,
+	sourceKey string,
+	size int64,
+	upload *s3.CreateMultipartUploadOutput,
+) ([]s3Types.CompletedPart, error) {
+	var parts []s3Types.CompletedPart
+
+	for partNumber, offset := int32(1), int64(0); offset < size; partNumber, offset = partNumber+1, offset+targetProvider.partSize {
+		length := targetProvider.partSize
+		if offset+length > size {
+			length = size - offset
+		}
+
+		body, err := provider.readRange(ctx, sourceBucket, sourceKey, offset, length)
+		if err != nil {
+			return nil, err
+		}
+
+		var output *s3.UploadPartOutput
+		err = targetProvider.limited(ctx, length, func() (err error) {
+			output, err = targetProvider.s3Client.uploadPart(ctx, &s3.UploadPartInput{
+				Bucket:        upload.Bucket,
+				Key:           upload.Key,
+				UploadId:      upload.UploadId,
+				PartNumber:    partNumber,
+				Body:          bytes.NewReader(body),
+				ContentLength: length,
+			})
+			return
+		})()
+		if err != nil {
+			return nil, fmt.Errorf("upload of part %d failed: %w", partNumber, err)
+		}
+
+		parts = append(parts, s3Types.CompletedPart{ETag: output.ETag, PartNumber: partNumber})
+	}
+
+	return parts, nil
+}
+
+func (provider S3Provider) readRange(ctx context.Context, bucket string, key string, offset int64, length int64) (body []byte, err error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	// empty objects can not be requested by range
+	if length > 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	}
+
+	err = provider.limited(ctx, length, func() (err error) {
+		body, err = provider.s3Client.get(ctx, input)
+		return
+	})()
+
+	return
+}
@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// in memory buckets, only the calls needed by a streamed copy are implemented
+type fakeTransferClient struct {
+	s3Client
+	objects map[string][]byte
+	parts   map[int32][]byte
+	aborted bool
+	failAt  int32
+}
+
+func (client *fakeTransferClient) get(ctx context.Context, input *s3.GetObjectInput) ([]byte, error) {
+	body := client.objects[*input.Bucket+"/"+*input.Key]
+	if input.Range != nil {
+		var start, end int
+		fmt.Sscanf(*input.Range, "bytes=%d-%d", &start, &end)
+		body = body[start : end+1]
+	}
+
+	return body, nil
+}
+
+func (client *fakeTransferClient) put(ctx context.Context, input *s3.PutObjectInput) error {
+	body, err := io.ReadAll(input.Body)
+	client.objects[*input.Bucket+"/"+*input.Key] = body
+	return err
+}
+
+func (client *fakeTransferClient) createMultipart(ctx context.Context, input *s3.CreateMultipartUploadInput) (*s3.CreateMultipartUploadOutput, error) {
+	client.parts = make(map[int32][]byte)
+	return &s3.CreateMultipartUploadOutput{Bucket: input.Bucket, Key: input.Key, UploadId: aws.String("upload")}, nil
+}
+
+func (client *fakeTransferClient) uploadPart(ctx context.Context, input *s3.UploadPartInput) (*s3.UploadPartOutput, error) {
+	if input.PartNumber == client.failAt {
+		return nil, fmt.Errorf("boom")
+	}
+	body, err := io.ReadAll(input.Body)
+	client.parts[input.PartNumber] = body
+	return &s3.UploadPartOutput{ETag: aws.String(fmt.Sprint(input.PartNumber))}, err
+}
+
+func (client *fakeTransferClient) completeMultipart(ctx context.Context, input *s3.CompleteMultipartUploadInput) error {
+	var body []byte
+	for _, part := range input.MultipartUpload.Parts {
+		body = append(body, client.parts[part.PartNumber]...)
+	}
+	client.objects[*input.Bucket+"/"+*input.Key] = body
+	return nil
+}
+
+func (client *fakeTransferClient) abortMultipart(ctx context.Context, input *s3.AbortMultipartUploadInput) error {
+	client.aborted = true
+	return nil
+}
+
+func TestStreamCopy(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name     string
+		content  string
+		partSize int64
+		failAt   int32
+		aborted  bool
+	}{
+		{
+			name:     "empty object",
+			content:  "",
+			partSize: 4,
+		},
+		{
+			name:     "single put",
+			content:  "abcd",
+			partSize: 4,
+		},
+		{
+			name:     "multipart with smaller last part",
+			content:  "abcdefghij",
+			partSize: 4,
+		},
+		{
+			name:     "failing part aborts the upload",
+			content:  "abcdefghij",
+			partSize: 4,
+			failAt:   2,
+			aborted:  true,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			sourceClient := &fakeTransferClient{objects: map[string][]byte{"source/a": []byte(subtest.content)}}
+			targetClient := &fakeTransferClient{objects: map[string][]byte{}, failAt: subtest.failAt}
+			source := S3Provider{s3Client: sourceClient}
+			target := S3Provider{s3Client: targetClient, partSize: subtest.partSize}
+
+			// act
+			err := source.streamCopy(context.Background(), target, "source", "a", int64(len(subtest.content)), "target", "b")
+
+			// assert
+			if subtest.aborted {
+				if err == nil || !targetClient.aborted || !strings.Contains(err.Error(), "part 2") {
+					test.Errorf("test %q failed for upload not aborted: %v", subtest.name, err)
+				}
+				return
+			}
+			if err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			if copied := targetClient.objects["target/b"]; !bytes.Equal(copied, []byte(subtest.content)) {
+				test.Errorf("test %q failed for %q != %q", subtest.name, copied, subtest.content)
+			}
+		})
+	}
+}
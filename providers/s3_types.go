@@ -2,7 +2,9 @@ package providers
 
 import (
 	"context"
+	"io"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
 	"smartclip.de/cloud-cleaner/resources"
@@ -25,10 +27,20 @@ func (resource s3BaseRuntimeResource) getPrefix() string {
 
 // used for mocking
 type s3Client interface {
-	listS3(*s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error)
-	copy(*s3.CopyObjectInput) error
-	delete(*s3.DeleteObjectInput) error
-	buckets() (*s3.ListBucketsOutput, error)
+	listS3(context.Context, *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error)
+	copy(context.Context, *s3.CopyObjectInput) error
+	delete(context.Context, *s3.DeleteObjectInput) error
+	head(context.Context, *s3.HeadObjectInput) (*s3.HeadObjectOutput, error)
+	restore(context.Context, *s3.RestoreObjectInput) error
+	getTags(context.Context, *s3.GetObjectTaggingInput) (*s3.GetObjectTaggingOutput, error)
+	putTags(context.Context, *s3.PutObjectTaggingInput) error
+	get(context.Context, *s3.GetObjectInput) ([]byte, error)
+	put(context.Context, *s3.PutObjectInput) error
+	createMultipart(context.Context, *s3.CreateMultipartUploadInput) (*s3.CreateMultipartUploadOutput, error)
+	uploadPart(context.Context, *s3.UploadPartInput) (*s3.UploadPartOutput, error)
+	completeMultipart(context.Context, *s3.CompleteMultipartUploadInput) error
+	abortMultipart(context.Context, *s3.AbortMultipartUploadInput) error
+	buckets(context.Context) (*s3.ListBucketsOutput, error)
 }
 
 // minimal s3 client wrapper for easier mocking
@@ -36,25 +48,84 @@ type s3ListingClient struct {
 	*s3.Client
 }
 
-func (client s3ListingClient) listS3(s3ObjectFilter *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
-	return client.ListObjectsV2(context.TODO(), s3ObjectFilter)
+func (client s3ListingClient) listS3(ctx context.Context, s3ObjectFilter *s3.ListObjectsV2Input) (*s3.ListObjectsV2Output, error) {
+	return client.ListObjectsV2(ctx, s3ObjectFilter)
 }
 
-func (client s3ListingClient) copy(input *s3.CopyObjectInput) error {
-	_, err := client.CopyObject(context.TODO(), input)
+func (client s3ListingClient) copy(ctx context.Context, input *s3.CopyObjectInput) error {
+	_, err := client.CopyObject(ctx, input)
 	return err
 }
 
-func (client s3ListingClient) delete(input *s3.DeleteObjectInput) error {
-	_, err := client.DeleteObject(context.TODO(), input)
+func (client s3ListingClient) delete(ctx context.Context, input *s3.DeleteObjectInput) error {
+	_, err := client.DeleteObject(ctx, input)
 	return err
 }
 
-func (client s3ListingClient) buckets() (*s3.ListBucketsOutput, error) {
-	return client.ListBuckets(context.TODO(), &s3.ListBucketsInput{})
+func (client s3ListingClient) head(ctx context.Context, input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	return client.HeadObject(ctx, input)
+}
+
+func (client s3ListingClient) restore(ctx context.Context, input *s3.RestoreObjectInput) error {
+	_, err := client.RestoreObject(ctx, input)
+	return err
+}
+
+func (client s3ListingClient) getTags(ctx context.Context, input *s3.GetObjectTaggingInput) (*s3.GetObjectTaggingOutput, error) {
+	return client.GetObjectTagging(ctx, input)
+}
+
+func (client s3ListingClient) putTags(ctx context.Context, input *s3.PutObjectTaggingInput) error {
+	_, err := client.PutObjectTagging(ctx, input)
+	return err
+}
+
+// reads the whole (ranged) body so it can be uploaded with a known length
+func (client s3ListingClient) get(ctx context.Context, input *s3.GetObjectInput) ([]byte, error) {
+	output, err := client.GetObject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	return io.ReadAll(output.Body)
+}
+
+func (client s3ListingClient) put(ctx context.Context, input *s3.PutObjectInput) error {
+	_, err := client.PutObject(ctx, input)
+	return err
+}
+
+func (client s3ListingClient) createMultipart(ctx context.Context, input *s3.CreateMultipartUploadInput) (*s3.CreateMultipartUploadOutput, error) {
+	return client.CreateMultipartUpload(ctx, input)
+}
+
+func (client s3ListingClient) uploadPart(ctx context.Context, input *s3.UploadPartInput) (*s3.UploadPartOutput, error) {
+	return client.UploadPart(ctx, input)
+}
+
+func (client s3ListingClient) completeMultipart(ctx context.Context, input *s3.CompleteMultipartUploadInput) error {
+	_, err := client.CompleteMultipartUpload(ctx, input)
+	return err
+}
+
+func (client s3ListingClient) abortMultipart(ctx context.Context, input *s3.AbortMultipartUploadInput) error {
+	_, err := client.AbortMultipartUpload(ctx, input)
+	return err
+}
+
+func (client s3ListingClient) buckets(ctx context.Context) (*s3.ListBucketsOutput, error) {
+	return client.ListBuckets(ctx, &s3.ListBucketsInput{})
 }
 
 type S3Provider struct {
 	BaseProvider
-	s3Client s3Client
+	s3Client  s3Client
+	awsConfig aws.Config
+	endpoint  string
+	// streamed copies between providers use multipart uploads above this size
+	partSize int64
+	// shared by all operations using this provider (nil if unlimited)
+	requestLimiter *types.RateLimiter
+	byteLimiter    *types.RateLimiter
 }
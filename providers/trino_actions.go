@@ -1,63 +1,216 @@
 package providers
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 
+	"github.com/trinodb/trino-go-client/trino"
+
+	"smartclip.de/cloud-cleaner/partitions"
 	"smartclip.de/cloud-cleaner/types"
 )
 
+// registers the location of every partition on the target table
+// or, if the target has "copydata" set, replaces the rows of the target partition with the ones of the source partition
+// both are safe to repeat (retries, resumed journals) and run on the connection of this provider
 func (provider TrinoClient) CopyPartition(
+	ctx context.Context,
 	partititons types.PartitionList,
 	source types.RuntimeResource,
 	target types.RuntimeResource,
 ) (types.PreparedActions, error) {
+	var (
+		preparedActions types.PreparedActions
+		errs            []error
+	)
+
+	sourceResource, _ := source.(*trinoRuntimeResource) // was validated at resource creation
+	targetResource, ok := target.(*trinoRuntimeResource)
+	if !ok {
+		return nil, fmt.Errorf("target %q of trino copy is no trino resource", target.GetResourceName())
+	}
+	if targetProvider := target.GetProvider(); targetProvider == nil || targetProvider.GetProviderName() != provider.GetProviderName() {
+		return nil, fmt.Errorf("target %q of trino copy does not belong to provider %q of source %q", target.GetResourceName(), provider.GetProviderName(), source.GetResourceName())
+	}
+	if len(sourceResource.PartitionSpec) != len(targetResource.PartitionSpec) {
+		return nil, fmt.Errorf("partition specs of %q and %q differ in length", source.GetResourceName(), target.GetResourceName())
+	}
+	if !targetResource.copyData && targetResource.partitionLocation == nil {
+		return nil, fmt.Errorf("target %q has neither partitionlocation nor copydata configured", target.GetResourceName())
+	}
+
+	for _, partition := range partititons {
+		var action func() error
+		if targetResource.copyData {
+			// rows of an interrupted or already completed insert are removed first
+			deleteSQL := deletePartitionSQL(targetResource, partition)
+			insertSQL := insertPartitionSQL(sourceResource, targetResource, partition)
+			log.Printf("prepare %s; %s", deleteSQL, insertSQL)
+			action = provider.statementAction(ctx, deleteSQL, insertSQL)
+		} else {
+			location, err := renderPartitionLocation(targetResource, partition)
+			if err != nil {
+				errs = append(errs, prepareError(source, partition, err))
+				continue
+			}
+			sql := partitionProcedureSQL("register_partition", targetResource, partition, location)
+			log.Printf("prepare %s", sql)
+			registerAction := provider.statementAction(ctx, sql)
+			action = func() error {
+				if err := registerAction(); err != nil && !alreadyRegistered(err, location) {
+					return err
+				}
+				return nil
+			}
+		}
 
-	return nil, nil
+		preparedActions = append(preparedActions, types.PreparedPartitionAction{
+			Partition: partition,
+			Action:    action,
+		})
+	}
+
+	return preparedActions, errors.Join(errs...)
 }
 
-func (provider TrinoClient) RemovePartition(partititons types.PartitionList, source types.RuntimeResource) (types.PreparedActions, error) {
+func (provider TrinoClient) RemovePartition(ctx context.Context, partititons types.PartitionList, source types.RuntimeResource) (types.PreparedActions, error) {
 	var preparedActions types.PreparedActions
 
 	resource, _ := source.(*trinoRuntimeResource) // was validated at resource creation
 
-	columns := make([]string, len(resource.PartitionSpec))
-	for idx, column := range resource.PartitionSpec {
-		columns[idx] = fmt.Sprintf(`'%s'`, column.Name)
-	}
-
 	for _, partition := range partititons {
-		rawPartitionVals := partition.GetValues()
-		partitionVals := make([]string, len(rawPartitionVals))
-		for idx, val := range rawPartitionVals {
-			columns[idx] = fmt.Sprintf(`'%s'`, val)
-		}
-
-		sql := fmt.Sprintf(
-			"system.unregister_partition(%s, %s, ARRAY[%s], ARRAY[%s])",
-			resource.schema,
-			resource.table,
-			strings.Join(columns, ","),
-			strings.Join(partitionVals, ","),
-		)
+		sql := partitionProcedureSQL("unregister_partition", resource, partition, "")
 
 		log.Printf("prepare %s", sql)
 
-		action := func() error {
+		preparedActions = append(preparedActions, types.PreparedPartitionAction{
+			Partition: partition,
+			Action:    provider.statementAction(ctx, sql),
+		})
+	}
+
+	return preparedActions, nil
+}
+
+// the statements run one after another, the first failing one stops the action
+func (provider TrinoClient) statementAction(ctx context.Context, statements ...string) func() error {
+	return func() error {
+		for _, sql := range statements {
 			log.Printf("execute: %s", sql)
-			if _, err := provider.db.Query(sql); err != nil {
+			if err := provider.statementLimiter.Wait(ctx, 1); err != nil {
 				return err
 			}
+			rows, err := provider.db.QueryContext(ctx, sql)
+			if err != nil {
+				return err
+			}
+			if err = rows.Close(); err != nil {
+				return err
+			}
+		}
 
-			return nil
+		return nil
+	}
+}
+
+// a repeated registration fails with ALREADY_EXISTS, which is only fine if the partition points to the same location
+func alreadyRegistered(err error, location string) bool {
+	var queryErr *trino.ErrQueryFailed
+	if !errors.As(err, &queryErr) || queryErr.Reason == nil {
+		return false
+	}
+
+	return strings.HasSuffix(queryErr.Reason.Error(), "is already registered with location "+location)
+}
+
+// call of a hive connector partition procedure, the location is only passed if not empty
+func partitionProcedureSQL(procedure string, resource *trinoRuntimeResource, partition types.Partition, location string) string {
+	values := partition.GetValues()
+	columns := make([]string, len(resource.PartitionSpec))
+	partitionVals := make([]string, len(resource.PartitionSpec))
+	for idx, column := range resource.PartitionSpec {
+		columns[idx] = sqlString(column.Name)
+		partitionVals[idx] = sqlString(values[idx])
+	}
+
+	arguments := []string{
+		sqlString(resource.schema),
+		sqlString(resource.table),
+		fmt.Sprintf("ARRAY[%s]", strings.Join(columns, ", ")),
+		fmt.Sprintf("ARRAY[%s]", strings.Join(partitionVals, ", ")),
+	}
+	if location != "" {
+		arguments = append(arguments, sqlString(location))
+	}
+
+	return fmt.Sprintf("CALL %s.system.%s(%s)", resource.catalog, procedure, strings.Join(arguments, ", "))
+}
+
+func insertPartitionSQL(source *trinoRuntimeResource, target *trinoRuntimeResource, partition types.Partition) string {
+	return fmt.Sprintf(
+		"INSERT INTO %s.%s.%s SELECT * FROM %s.%s.%s WHERE %s",
+		target.catalog,
+		target.schema,
+		target.table,
+		source.catalog,
+		source.schema,
+		source.table,
+		partitionPredicate(source, partition),
+	)
+}
+
+// the hive connector only deletes whole partitions, so the columns are compared untouched with literals of their data type
+func deletePartitionSQL(resource *trinoRuntimeResource, partition types.Partition) string {
+	values := partition.GetValues()
+	predicates := make([]string, len(resource.PartitionSpec))
+	for idx, column := range resource.PartitionSpec {
+		literal := sqlString(values[idx])
+		switch column.DataType {
+		case partitions.Date:
+			literal = "DATE " + literal
+		case partitions.DateTime:
+			literal = "TIMESTAMP " + literal
+		case partitions.Time:
+			literal = "TIME " + literal
+		case partitions.Int:
+			literal = "BIGINT " + literal
 		}
+		predicates[idx] = fmt.Sprintf("%s = %s", column.Name, literal)
+	}
 
-		preparedActions = append(preparedActions, types.PreparedPartitionAction{
-			Partition: partition,
-			Action:    action,
-		})
+	return fmt.Sprintf("DELETE FROM %s.%s.%s WHERE %s", resource.catalog, resource.schema, resource.table, strings.Join(predicates, " AND "))
+}
+
+// partition columns are compared as varchar just like they are collected
+func partitionPredicate(resource *trinoRuntimeResource, partition types.Partition) string {
+	values := partition.GetValues()
+	predicates := make([]string, len(resource.PartitionSpec))
+	for idx, column := range resource.PartitionSpec {
+		predicates[idx] = fmt.Sprintf("CAST(%s AS VARCHAR) = %s", column.Name, sqlString(values[idx]))
 	}
 
-	return preparedActions, nil
+	return strings.Join(predicates, " AND ")
+}
+
+func renderPartitionLocation(resource *trinoRuntimeResource, partition types.Partition) (string, error) {
+	columns := make(map[string]string)
+	values := partition.GetValues()
+	for idx, spec := range resource.PartitionSpec {
+		columns[spec.Name] = values[idx]
+	}
+
+	var location bytes.Buffer
+	if err := resource.partitionLocation.Execute(&location, columns); err != nil {
+		return "", fmt.Errorf("could not render partitionlocation of %q: %w", resource.Name, err)
+	}
+
+	return location.String(), nil
+}
+
+func sqlString(str string) string {
+	return "'" + strings.ReplaceAll(str, "'", "''") + "'"
 }
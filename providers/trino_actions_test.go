@@ -0,0 +1,188 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"text/template"
+
+	"github.com/trinodb/trino-go-client/trino"
+
+	"smartclip.de/cloud-cleaner/partitions"
+	"smartclip.de/cloud-cleaner/resources"
+	"smartclip.de/cloud-cleaner/types"
+)
+
+func TestTrinoCopyStatements(test *testing.T) {
+	spec := []types.PartitionSpec{{Name: "dt", DataType: partitions.Date}, {Name: "country", DataType: partitions.String}}
+	source := &trinoRuntimeResource{BaseResource: resources.BaseResource{PartitionSpec: spec}, catalog: "hive", schema: "raw", table: "events"}
+	target := &trinoRuntimeResource{BaseResource: resources.BaseResource{PartitionSpec: spec}, catalog: "hive", schema: "archive", table: "events"}
+	target.partitionLocation = template.Must(template.New("target").Option("missingkey=error").Parse("s3://archive/events/dt={{.dt}}/country={{.country}}"))
+	partition := &TrinoPartition{BasePartition: partitions.BasePartition{PartitionValues: []string{"2024-01-01", "o'hara"}}}
+
+	location, err := renderPartitionLocation(target, partition)
+	if err != nil {
+		test.Fatalf("rendering location failed: %s", err)
+	}
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		input    func() string
+		expected string
+	}{
+		{
+			name:     "register partition",
+			input:    func() string { return partitionProcedureSQL("register_partition", target, partition, location) },
+			expected: `CALL hive.system.register_partition('archive', 'events', ARRAY['dt', 'country'], ARRAY['2024-01-01', 'o''hara'], 's3://archive/events/dt=2024-01-01/country=o''hara')`,
+		},
+		{
+			name:     "unregister partition",
+			input:    func() string { return partitionProcedureSQL("unregister_partition", source, partition, "") },
+			expected: `CALL hive.system.unregister_partition('raw', 'events', ARRAY['dt', 'country'], ARRAY['2024-01-01', 'o''hara'])`,
+		},
+		{
+			name:     "insert partition",
+			input:    func() string { return insertPartitionSQL(source, target, partition) },
+			expected: `INSERT INTO hive.archive.events SELECT * FROM hive.raw.events WHERE CAST(dt AS VARCHAR) = '2024-01-01' AND CAST(country AS VARCHAR) = 'o''hara'`,
+		},
+		{
+			name:     "delete partition before insert",
+			input:    func() string { return deletePartitionSQL(target, partition) },
+			expected: `DELETE FROM hive.archive.events WHERE dt = DATE '2024-01-01' AND country = 'o''hara'`,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			result := subtest.input()
+
+			// assert
+			if result != subtest.expected {
+				test.Errorf("test %q failed for %s != %s", subtest.name, result, subtest.expected)
+			}
+		})
+	}
+}
+
+func TestTrinoAlreadyRegistered(test *testing.T) {
+	location := "s3://archive/events/dt=2024-01-01"
+	registered := func(message string) error {
+		return fmt.Errorf("execute: %w", &trino.ErrQueryFailed{StatusCode: 200, Reason: errors.New(message)})
+	}
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "same location",
+			err:      registered("ALREADY_EXISTS: Partition [dt=2024-01-01] is already registered with location " + location),
+			expected: true,
+		},
+		{
+			name:     "other location",
+			err:      registered("ALREADY_EXISTS: Partition [dt=2024-01-01] is already registered with location s3://raw/events/dt=2024-01-01"),
+			expected: false,
+		},
+		{
+			name:     "other failure",
+			err:      registered("NOT_FOUND: Table 'archive.events' not found"),
+			expected: false,
+		},
+		{
+			name:     "no query failure",
+			err:      errors.New("is already registered with location " + location),
+			expected: false,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			result := alreadyRegistered(subtest.err, location)
+
+			// assert
+			if result != subtest.expected {
+				test.Errorf("test %q failed for %t != %t", subtest.name, result, subtest.expected)
+			}
+		})
+	}
+}
+
+func TestTrinoCopyOtherProvider(test *testing.T) {
+	spec := []types.PartitionSpec{{Name: "dt", DataType: partitions.Date}}
+	sourceProvider := TrinoClient{BaseProvider: BaseProvider{Name: "warehouse"}}
+	source := &trinoRuntimeResource{BaseResource: resources.BaseResource{Name: "raw", PartitionSpec: spec}, catalog: "hive", schema: "raw", table: "events", copyData: true}
+	source.SetProvider(&sourceProvider)
+
+	// arrange
+	testTabel := []struct {
+		name     string
+		provider string
+		err      string
+	}{
+		{
+			name:     "same provider",
+			provider: "warehouse",
+		},
+		{
+			name:     "other provider",
+			provider: "lake",
+			err:      `target "archive" of trino copy does not belong to provider "warehouse" of source "raw"`,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			target := &trinoRuntimeResource{BaseResource: resources.BaseResource{Name: "archive", PartitionSpec: spec}, catalog: "hive", schema: "archive", table: "events", copyData: true}
+			target.SetProvider(&TrinoClient{BaseProvider: BaseProvider{Name: subtest.provider}})
+
+			// act
+			_, err := sourceProvider.CopyPartition(context.Background(), nil, source, target)
+
+			// assert
+			if subtest.err == "" && err != nil {
+				test.Errorf("test %q failed for %s", subtest.name, err)
+			}
+			if subtest.err != "" && (err == nil || err.Error() != subtest.err) {
+				test.Errorf("test %q failed for %v != %s", subtest.name, err, subtest.err)
+			}
+		})
+	}
+}
+
+func TestTrinoCopyPartialFailure(test *testing.T) {
+	spec := []types.PartitionSpec{{Name: "dt", DataType: partitions.Date}, {Name: "country", DataType: partitions.String}}
+	provider := TrinoClient{BaseProvider: BaseProvider{Name: "warehouse"}}
+	source := &trinoRuntimeResource{BaseResource: resources.BaseResource{Name: "raw", PartitionSpec: spec}, catalog: "hive", schema: "raw", table: "events"}
+	source.SetProvider(&provider)
+	target := &trinoRuntimeResource{BaseResource: resources.BaseResource{Name: "archive", PartitionSpec: spec}, catalog: "hive", schema: "archive", table: "events"}
+	target.SetProvider(&provider)
+	// only the "bad" country references a column which does not exist
+	target.partitionLocation = template.Must(template.New("target").Option("missingkey=error").Parse(`s3://archive/{{if eq .country "bad"}}{{.missing}}{{end}}dt={{.dt}}/country={{.country}}`))
+
+	partition := func(country string) types.Partition {
+		return &TrinoPartition{BasePartition: partitions.BasePartition{PartitionValues: []string{"2024-01-01", country}}}
+	}
+
+	// act
+	preparedActions, err := provider.CopyPartition(context.Background(), types.PartitionList{partition("de"), partition("bad"), partition("fr")}, source, target)
+
+	// assert
+	if err == nil {
+		test.Errorf("test %q failed for missing error", "partial failure")
+	}
+	var countries []string
+	for _, preparedAction := range preparedActions {
+		countries = append(countries, preparedAction.GetValues()[1])
+	}
+	if !reflect.DeepEqual(countries, []string{"de", "fr"}) {
+		test.Errorf("test %q failed for %v != %v", "partial failure", countries, []string{"de", "fr"})
+	}
+}
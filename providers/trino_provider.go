@@ -1,10 +1,12 @@
 package providers
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"database/sql"
@@ -25,12 +27,24 @@ type trinoRuntimeResource struct {
 	catalog string
 	schema  string
 	table   string
+	// where partitions copied into this table are registered, references partition columns by name e.g. "{{.dt}}"
+	partitionLocation *template.Template
+	// copies into this table insert the rows of the source instead of registering its location
+	copyData bool
 }
 
 func (partition *TrinoPartition) GetTimestamp() (time.Time, error) {
 	return time.Time{}, fmt.Errorf("trino partitions do not support timestamp related actions")
 }
 
+func (partition *TrinoPartition) GetSize() (int64, error) {
+	return 0, fmt.Errorf("trino partitions do not support size related actions")
+}
+
+func (partition *TrinoPartition) GetObjectCount() (int64, error) {
+	return 0, fmt.Errorf("trino partitions do not support object count related actions")
+}
+
 func (currentPartition *TrinoPartition) UpdatePartition(updatePartition types.Partition) error {
 	otherPartition, ok := updatePartition.(*TrinoPartition)
 	if !ok {
@@ -47,9 +61,11 @@ func (currentPartition *TrinoPartition) UpdatePartition(updatePartition types.Pa
 type TrinoClient struct {
 	BaseProvider
 	db *sql.DB
+	// statements of all operations using this provider share one limit (nil if unlimited)
+	statementLimiter *types.RateLimiter
 }
 
-func (provider *TrinoClient) Init(providerConf map[string]interface{}, errChan chan<- error, wg *sync.WaitGroup) {
+func (provider *TrinoClient) Init(ctx context.Context, providerConf map[string]interface{}, errChan chan<- error, wg *sync.WaitGroup) {
 	var host, catalog, schema string
 	val, ok := providerConf["config"]
 	if !ok {
@@ -106,6 +122,11 @@ func (provider *TrinoClient) Init(providerConf map[string]interface{}, errChan c
 		errChan <- err
 	}
 
+	// optional parameter
+	if provider.statementLimiter, err = makeRateLimiter(conf, "statementspersecond", provider.Name); err != nil {
+		errChan <- err
+	}
+
 	wg.Done()
 }
 
@@ -144,6 +165,25 @@ func (provider *TrinoClient) MakeRuntimResource(conf map[string]interface{}) (ty
 	resource.schema = parts[1]
 	resource.table = parts[2]
 
+	// optional parameter, only needed if the resource is the target of a copy
+	if tmp, ok = conf["partitionlocation"]; ok {
+		location, ok := tmp.(string)
+		if !ok || location == "" {
+			return nil, fmt.Errorf("partitionlocation field of resource %q is not of type string", resource.Name)
+		}
+		// unknown columns must not silently render as "<no value>"
+		if resource.partitionLocation, err = template.New(resource.Name).Option("missingkey=error").Parse(location); err != nil {
+			return nil, fmt.Errorf("partitionlocation of resource %q is no valid template: %w", resource.Name, err)
+		}
+	}
+
+	// optional parameter
+	if tmp, ok = conf["copydata"]; ok {
+		if resource.copyData, ok = tmp.(bool); !ok {
+			return nil, fmt.Errorf("copydata field of resource %q is not a boolean", resource.Name)
+		}
+	}
+
 	provider.Resources = append(provider.Resources, &resource)
 	return &resource, nil
 }
@@ -152,8 +192,8 @@ func (client TrinoClient) ResourceInputChan() chan<- types.RuntimeResource {
 	return client.InputChan
 }
 
-func (provider TrinoClient) CheckAccess(errChan chan<- error, wg *sync.WaitGroup) {
-	_, err := provider.db.Query("show tables")
+func (provider TrinoClient) CheckAccess(ctx context.Context, errChan chan<- error, wg *sync.WaitGroup) {
+	_, err := provider.db.QueryContext(ctx, "show tables")
 	if err != nil {
 		errChan <- err
 	}
@@ -162,64 +202,70 @@ func (provider TrinoClient) CheckAccess(errChan chan<- error, wg *sync.WaitGroup
 }
 
 // concurrent and mutable since every goroutine uniqually handles one resource
-func (client TrinoClient) CollectPartitions(errorChan chan<- error, wg *sync.WaitGroup) {
+func (client TrinoClient) CollectPartitions(ctx context.Context, errorChan chan<- error, wg *sync.WaitGroup) {
 	for r := range client.InputChan {
 		resource := r.(*trinoRuntimeResource)
-		log.Printf("trino collection start for %q", resource.Name)
 
-		sqlQuery := partionQueryString(resource)
-		log.Printf("trino query: %s", sqlQuery)
-		rows, err := client.db.Query(sqlQuery)
-		if err != nil {
-			errorChan <- err
-			return
+		if err := client.collectResourcePartitions(ctx, resource); err != nil {
+			errorChan <- &types.ContextError{Stage: "collect", Resource: resource.Name, Err: err}
 		}
+		wg.Done()
+	}
+}
 
-		for rows.Next() {
-			// generic interface because of trino row() type slice helps with arbitrary amount
-			// of partition columns since trino sql clients validates &row against being a pointer
-			// and scan() attribute having as many elements as columns specified in the sql query
-			var trinoPartitionStrings trino.NullSliceString
-			if err := rows.Scan(&trinoPartitionStrings); err != nil {
-				errorChan <- err
-				return
-			}
-
-			partitionValues := make([]string, len(resource.PartitionSpec))
-			for idx, partitionString := range trinoPartitionStrings.SliceString {
-				partitionValues[idx] = partitionString.String
-			}
-
-			partition := TrinoPartition{
-				BasePartition: partitions.BasePartition{
-					PartitionValues: partitionValues,
-					Resource:        resource,
-					CompletionWg:    &sync.WaitGroup{},
-				},
-			}
-
-			parsedValues, err := partitions.ParsePartitionString(resource.PartitionSpec, partition.PartitionValues)
-			if err != nil {
-				errorChan <- err
-				return
-			}
-			partition.TypedPartitionValues = parsedValues
-
-			// no checks for partition collision since if so trino / HMS would be broken already
-			hashId := partition.TypedPartitionValues.ToString()
-			resource.Partitions[hashId] = &partition
+func (client TrinoClient) collectResourcePartitions(ctx context.Context, resource *trinoRuntimeResource) error {
+	log.Printf("trino collection start for %q", resource.Name)
+
+	sqlQuery := partionQueryString(resource)
+	log.Printf("trino query: %s", sqlQuery)
+	if err := client.statementLimiter.Wait(ctx, 1); err != nil {
+		return err
+	}
+	rows, err := client.db.QueryContext(ctx, sqlQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		// generic interface because of trino row() type slice helps with arbitrary amount
+		// of partition columns since trino sql clients validates &row against being a pointer
+		// and scan() attribute having as many elements as columns specified in the sql query
+		var trinoPartitionStrings trino.NullSliceString
+		if err := rows.Scan(&trinoPartitionStrings); err != nil {
+			return err
 		}
-		rows.Close()
 
-		// Check for errors from iterating over rows.
-		if err := rows.Err(); err != nil {
-			errorChan <- err
-			return
+		partitionValues := make([]string, len(resource.PartitionSpec))
+		for idx, partitionString := range trinoPartitionStrings.SliceString {
+			partitionValues[idx] = partitionString.String
 		}
 
-		log.Printf("%s found %d partitions for table: %s", resource.Name, len(resource.Partitions), resource.table)
-		wg.Done()
+		partition := TrinoPartition{
+			BasePartition: partitions.BasePartition{
+				PartitionValues: partitionValues,
+				Resource:        resource,
+			},
+		}
+
+		parsedValues, err := partitions.ParsePartitionString(resource.PartitionSpec, partition.PartitionValues)
+		if err != nil {
+			return err
+		}
+		partition.TypedPartitionValues = parsedValues
+
+		// no checks for partition collision since if so trino / HMS would be broken already
+		hashId := partition.TypedPartitionValues.ToString()
+		resource.Partitions[hashId] = &partition
 	}
+
+	// Check for errors from iterating over rows.
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	log.Printf("%s found %d partitions for table: %s", resource.Name, len(resource.Partitions), resource.table)
+	return nil
 }
 
 func partionQueryString(resource *trinoRuntimeResource) string {
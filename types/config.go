@@ -0,0 +1,49 @@
+package types
+
+import "time"
+
+// config maps are decoded from json so numbers may arrive as float64 instead of int
+func ConfigInt(val interface{}) (int, bool) {
+	switch number := val.(type) {
+	case int:
+		return number, true
+	case int64:
+		return int(number), true
+	case float64:
+		if number != float64(int(number)) {
+			return 0, false
+		}
+		return int(number), true
+	default:
+		return 0, false
+	}
+}
+
+// like ConfigInt but fractions are allowed
+func ConfigFloat(val interface{}) (float64, bool) {
+	switch number := val.(type) {
+	case int:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case float64:
+		return number, true
+	default:
+		return 0, false
+	}
+}
+
+// durations are configured as strings like "90s" or "1h30m" and have to be positive
+func ConfigDuration(val interface{}) (time.Duration, bool) {
+	str, ok := val.(string)
+	if !ok {
+		return 0, false
+	}
+
+	duration, err := time.ParseDuration(str)
+	if err != nil || duration <= 0 {
+		return 0, false
+	}
+
+	return duration, true
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+const gigabyte = 1 << 30
+
+// how long a partition waits for its dependencies, zero values are inherited (see Inherit)
+type DependencyTimeout struct {
+	Base time.Duration
+	// added for every started gigabyte of the partition (e.g. a large copy in front of a delete)
+	PerGB time.Duration
+}
+
+// parses "dependencytimeout" and "dependencytimeoutpergb" of the global or operation config
+func MakeDependencyTimeout(conf map[string]interface{}, owner string) (timeout DependencyTimeout, err error) {
+	if val, ok := conf["dependencytimeout"]; ok {
+		if timeout.Base, ok = ConfigDuration(val); !ok {
+			return DependencyTimeout{}, fmt.Errorf("\"dependencytimeout\" of %s is not a positive duration string: %v", owner, val)
+		}
+	}
+
+	if val, ok := conf["dependencytimeoutpergb"]; ok {
+		if timeout.PerGB, ok = ConfigDuration(val); !ok {
+			return DependencyTimeout{}, fmt.Errorf("\"dependencytimeoutpergb\" of %s is not a positive duration string: %v", owner, val)
+		}
+	}
+
+	return
+}
+
+func (timeout DependencyTimeout) Inherit(parent DependencyTimeout) DependencyTimeout {
+	if timeout.Base == 0 {
+		timeout.Base = parent.Base
+	}
+	if timeout.PerGB == 0 {
+		timeout.PerGB = parent.PerGB
+	}
+
+	return timeout
+}
+
+// partitions without a known size only get the base timeout
+func (timeout DependencyTimeout) For(partition Partition) time.Duration {
+	if timeout.PerGB == 0 {
+		return timeout.Base
+	}
+
+	size, err := partition.GetSize()
+	if err != nil {
+		return timeout.Base
+	}
+	startedGigabytes := (size + gigabyte - 1) / gigabyte
+
+	return timeout.Base + time.Duration(startedGigabytes)*timeout.PerGB
+}
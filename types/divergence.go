@@ -0,0 +1,41 @@
+package types
+
+import "fmt"
+
+// kinds of differences found by read only comparisons of two resources
+const (
+	MissingObject = "missing" // only in the source
+	ExtraObject   = "extra"   // only in the target
+	SizeMismatch  = "size"
+	ETagMismatch  = "etag"
+)
+
+type ObjectDivergence struct {
+	Kind       string `json:"kind"`
+	Key        string `json:"key,omitempty"`
+	Target     string `json:"target,omitempty"`
+	SourceSize int64  `json:"sourcesize,omitempty"`
+	TargetSize int64  `json:"targetsize,omitempty"`
+	SourceETag string `json:"sourceetag,omitempty"`
+	TargetETag string `json:"targetetag,omitempty"`
+}
+
+// returned by the action of a partition whose target differs from its source
+type DivergenceError struct {
+	SourceObjects int                `json:"sourceobjects"`
+	TargetObjects int                `json:"targetobjects"`
+	SourceBytes   int64              `json:"sourcebytes"`
+	TargetBytes   int64              `json:"targetbytes"`
+	Divergences   []ObjectDivergence `json:"divergences"`
+}
+
+func (err *DivergenceError) Error() string {
+	return fmt.Sprintf(
+		"target diverges in %d objects (source %d objects with %d bytes, target %d objects with %d bytes)",
+		len(err.Divergences),
+		err.SourceObjects,
+		err.SourceBytes,
+		err.TargetObjects,
+		err.TargetBytes,
+	)
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// error enriched by the context it occurred in, every field besides Err is optional
+type ContextError struct {
+	Stage     string
+	Operation string
+	Resource  string
+	Partition string
+	Err       error
+}
+
+func (err *ContextError) Error() string {
+	var context []string
+	if err.Stage != "" {
+		context = append(context, err.Stage)
+	}
+	if err.Operation != "" {
+		context = append(context, fmt.Sprintf("operation %q", err.Operation))
+	}
+	if err.Resource != "" {
+		context = append(context, fmt.Sprintf("resource %q", err.Resource))
+	}
+	if err.Partition != "" {
+		context = append(context, fmt.Sprintf("partition %q", err.Partition))
+	}
+
+	return strings.Join(context, " ") + ": " + err.Err.Error()
+}
+
+func (err *ContextError) Unwrap() error {
+	return err.Err
+}
+
+// fills in context which was not known where the error was created
+func WithContext(err error, stage string, operation string, resource string) *ContextError {
+	contextErr, ok := err.(*ContextError)
+	if !ok {
+		contextErr = &ContextError{Err: err}
+	}
+
+	enriched := *contextErr
+	if enriched.Stage == "" {
+		enriched.Stage = stage
+	}
+	if enriched.Operation == "" {
+		enriched.Operation = operation
+	}
+	if enriched.Resource == "" {
+		enriched.Resource = resource
+	}
+
+	return &enriched
+}
+
+// drains the channel until it is closed (so no sender blocks forever) and joins everything received
+func CollectErrors(errChan <-chan error) error {
+	var errs []error
+	for err := range errChan {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// reverse of errors.Join so every error can be handled on its own
+func SplitErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, inner := range joined.Unwrap() {
+			errs = append(errs, SplitErrors(inner)...)
+		}
+		return errs
+	}
+
+	return []error{err}
+}
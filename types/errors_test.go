@@ -0,0 +1,89 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCollectAndSplitErrors(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name     string
+		input    []error
+		expected int
+	}{
+		{
+			name:     "no errors",
+			input:    []error{},
+			expected: 0,
+		},
+		{
+			name:     "nil errors are dropped",
+			input:    []error{nil, fmt.Errorf("a"), nil},
+			expected: 1,
+		},
+		{
+			name:     "every error is kept",
+			input:    []error{fmt.Errorf("a"), fmt.Errorf("b"), fmt.Errorf("c")},
+			expected: 3,
+		},
+		{
+			name:     "nested joins are flattened",
+			input:    []error{errors.Join(fmt.Errorf("a"), fmt.Errorf("b")), fmt.Errorf("c")},
+			expected: 3,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			errChan := make(chan error)
+			go func() {
+				for _, err := range subtest.input {
+					errChan <- err
+				}
+				close(errChan)
+			}()
+
+			// act
+			result := SplitErrors(CollectErrors(errChan))
+
+			// assert
+			if len(result) != subtest.expected {
+				test.Errorf("test %q failed for %d != %d", subtest.name, len(result), subtest.expected)
+			}
+		})
+	}
+}
+
+func TestWithContext(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name     string
+		input    error
+		expected string
+	}{
+		{
+			name:     "plain error gets wrapped",
+			input:    fmt.Errorf("boom"),
+			expected: `prepare operation "op" resource "res": boom`,
+		},
+		{
+			name:     "existing context is kept",
+			input:    &ContextError{Stage: "collect", Partition: "1", Err: fmt.Errorf("boom")},
+			expected: `collect operation "op" resource "res" partition "1": boom`,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			// act
+			result := WithContext(subtest.input, "prepare", "op", "res")
+
+			// assert
+			if result.Error() != subtest.expected {
+				test.Errorf("test %q failed for %q != %q", subtest.name, result.Error(), subtest.expected)
+			}
+		})
+	}
+}
@@ -3,3 +3,20 @@ package types
 type Exclude interface {
 	IgnorePartition(partitions PartitionList) (PartitionList, error)
 }
+
+// excludes comparing partitions with other resources, these have to be collected just like operation sources
+type ResourceReferrer interface {
+	ReferencedResources() []RuntimeResource
+}
+
+// resources referenced by any of the excludes, nested ones included
+func ReferencedResources(excludes ...Exclude) []RuntimeResource {
+	var resources []RuntimeResource
+	for _, exclude := range excludes {
+		if referrer, ok := exclude.(ResourceReferrer); ok {
+			resources = append(resources, referrer.ReferencedResources()...)
+		}
+	}
+
+	return resources
+}
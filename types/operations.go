@@ -1,5 +1,10 @@
 package types
 
+import (
+	"context"
+	"time"
+)
+
 /*
 this is likeyly the most important function of this app
 it has multiple responsebilities
@@ -7,7 +12,7 @@ it has multiple responsebilities
 1. provide the function executing one action for exactly one partition
 2. it must wait for all execution locks to complete first
 3. communicating (as a log message) what is happening
-4. clean up the partitinos execution lock by goling Partition.CloseCompleteChan()
+4. clean up the partitinos execution lock once it is done (see execution.ExecutionLocks)
 
 additionally it must not error when executed concurrently
 there may be provider which are sensitive to that
@@ -16,13 +21,28 @@ type PreparedActions []PreparedPartitionAction
 
 type PreparedPartitionAction struct {
 	Partition
+	Objects []PreparedObject
+	// partition level action of providers without object granularity (nil if objects carry the actions)
 	Action func() error
 }
 
+// object level details of a prepared action (used by plan files to detect drift and the execution journal)
+type PreparedObject struct {
+	Key    string       `json:"key"`
+	Target string       `json:"target,omitempty"`
+	Size   int64        `json:"size"`
+	ETag   string       `json:"etag"`
+	Action func() error `json:"-"`
+}
+
 // this is just a dummy - since it has no source there is nothing to operate on
 type RuntimeOperation interface {
 	GetOperationName() string
 	GetDependencies() []string
+	GetConcurrency() int
+	GetContinueOnError() bool
+	GetRetryPolicy() RetryPolicy
+	GetDependencyTimeout() DependencyTimeout
 	PartitionsWithExcludes() error
 }
 
@@ -30,11 +50,46 @@ type RuntimeOperationSingle interface {
 	RuntimeOperation
 	GetOperationSource() RuntimeResource
 	GetKeptPartitions() (PartitionList, error)
+	SetKeptPartitions(PartitionList)
+	// errors if the kept partitions exceed the safety guards of the operation
+	CheckSafetyGuards() error
 
-	ExecuteOperation() (PreparedActions, error)
+	// on partial failure the actions of all healthy partitions are returned alongside the error
+	ExecuteOperation(context.Context) (PreparedActions, error)
 }
 
 type RuntimeOperationDouble interface {
 	RuntimeOperationSingle
 	GetOperationTarget() RuntimeResource
 }
+
+// operations which never modify a resource, they run even without --armed and are not journaled
+type ReadOnlyOperation interface {
+	ReadOnly() bool
+}
+
+// operations comparing source and target themselves accept partitions which already exist in the target
+type TargetComparingOperation interface {
+	ComparesTarget() bool
+}
+
+// operations deleting from their source have to run after every other operation reading it
+type SourceRemover interface {
+	RemovesSource() bool
+}
+
+// operations whose partition actions wait for an external process (e.g. a glacier restore)
+type WaitingOperation interface {
+	// upper bound of the wait, zero if the operation does not wait
+	MaxWait() time.Duration
+}
+
+func IsReadOnly(operation RuntimeOperationSingle) bool {
+	readOnly, ok := operation.(ReadOnlyOperation)
+	return ok && readOnly.ReadOnly()
+}
+
+func IsSourceRemover(operation RuntimeOperationSingle) bool {
+	remover, ok := operation.(SourceRemover)
+	return ok && remover.RemovesSource()
+}
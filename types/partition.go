@@ -2,12 +2,9 @@ package types
 
 import (
 	"strings"
-	"sync"
 	"time"
 )
 
-type PartitionDependencies []*sync.WaitGroup
-
 // have this type implement sort interface
 type PartitionList []Partition
 
@@ -53,14 +50,12 @@ func (vals TypedPartitionValueList) ToString() string {
 
 type Partition interface {
 	GetValues() []string
-	GetDependencies() PartitionDependencies
 	GetParsedValues() TypedPartitionValueList
+	// resource the partition was collected from, its partition spec names the values
+	GetResource() RuntimeResource
 	GetTimestamp() (time.Time, error)
+	GetSize() (int64, error)
+	GetObjectCount() (int64, error)
 
-	AddDependencies(*sync.WaitGroup)
 	UpdatePartition(Partition) error
-
-	RegisterCompletionLock() *sync.WaitGroup
-	WaitForCompletion()
-	CloseCompleteChan()
 }
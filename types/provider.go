@@ -1,24 +1,70 @@
 package types
 
-import "sync"
+import (
+	"context"
+	"sync"
+	"time"
+)
 
 type PartitionProvider interface {
-	Init(map[string]interface{}, chan<- error, *sync.WaitGroup)
+	Init(context.Context, map[string]interface{}, chan<- error, *sync.WaitGroup)
 	MakeRuntimResource(map[string]interface{}) (RuntimeResource, error)
-	CheckAccess(chan<- error, *sync.WaitGroup)
+	CheckAccess(context.Context, chan<- error, *sync.WaitGroup)
 
 	ResourceInputChan() chan<- RuntimeResource
-	CollectPartitions(chan<- error, *sync.WaitGroup)
+	CollectPartitions(context.Context, chan<- error, *sync.WaitGroup)
 	GetRelatedResources() []RuntimeResource
 	GetResourceConcurrency() int
+	GetActionConcurrency() int
+	GetRetryPolicy() RetryPolicy
+	InheritRetryPolicy(RetryPolicy)
+	GetListingRetries() int64
 	GetProviderName() string
 	GetProviderType() ProviderType
 }
 
 type ReplicateProvider interface {
-	CopyPartition(partitions PartitionList, source RuntimeResource, target RuntimeResource) (PreparedActions, error)
+	CopyPartition(ctx context.Context, partitions PartitionList, source RuntimeResource, target RuntimeResource) (PreparedActions, error)
+}
+
+// copies, verifies and only then removes the source partition
+type MoveProvider interface {
+	MovePartition(ctx context.Context, partitions PartitionList, source RuntimeResource, target RuntimeResource) (PreparedActions, error)
+}
+
+// rewrites the objects of a partition in place with another storage class
+type TransitionProvider interface {
+	TransitionPartition(ctx context.Context, partitions PartitionList, source RuntimeResource, storageClass string, minObjectSize int64) (PreparedActions, error)
+}
+
+// compares every object of the source partition with the same partition in the target
+// the actions fail with a *DivergenceError if they differ
+type VerifyProvider interface {
+	VerifyPartition(ctx context.Context, partitions PartitionList, source RuntimeResource, target RuntimeResource) (PreparedActions, error)
+}
+
+// copies only objects missing or differing in the target and optionally deletes extra target objects
+type SyncProvider interface {
+	SyncPartition(ctx context.Context, partitions PartitionList, source RuntimeResource, target RuntimeResource, deleteExtra bool) (PreparedActions, error)
+}
+
+type TagProvider interface {
+	TagPartition(ctx context.Context, partitions PartitionList, source RuntimeResource, options TagOptions) (PreparedActions, error)
+}
+
+type RestoreOptions struct {
+	Days int
+	Tier string
+	// the action only completes once every object is restored
+	Wait         bool
+	PollInterval time.Duration
+}
+
+// requests temporary copies of archived objects
+type RestoreProvider interface {
+	RestorePartition(ctx context.Context, partitions PartitionList, source RuntimeResource, options RestoreOptions) (PreparedActions, error)
 }
 
 type RemoveProvider interface {
-	RemovePartition(partitions PartitionList, source RuntimeResource) (PreparedActions, error)
+	RemovePartition(ctx context.Context, partitions PartitionList, source RuntimeResource) (PreparedActions, error)
 }
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// token bucket shared by everything using the same provider, a nil limiter does not limit at all
+type RateLimiter struct {
+	mutex  sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// the bucket holds at most one second worth of tokens (but at least one)
+func NewRateLimiter(perSecond float64) *RateLimiter {
+	burst := math.Max(perSecond, 1)
+	return &RateLimiter{
+		rate:   perSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// blocks until n tokens are available or the context is done
+// requests larger than the bucket (e.g. big objects against a byte limit) are allowed and pay off their debt by waiting
+func (limiter *RateLimiter) Wait(ctx context.Context, n float64) error {
+	if limiter == nil {
+		return nil
+	}
+
+	limiter.mutex.Lock()
+	now := time.Now()
+	limiter.tokens = math.Min(limiter.burst, limiter.tokens+now.Sub(limiter.last).Seconds()*limiter.rate)
+	limiter.last = now
+	limiter.tokens -= n
+	delay := time.Duration(-limiter.tokens / limiter.rate * float64(time.Second))
+	limiter.mutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWait(test *testing.T) {
+	// arrange
+	testTabel := []struct {
+		name      string
+		perSecond float64
+		requests  []float64
+		minimum   time.Duration
+	}{
+		{
+			name:      "burst is not delayed",
+			perSecond: 100,
+			requests:  []float64{50, 50},
+			minimum:   0,
+		},
+		{
+			name:      "exceeding the burst waits for new tokens",
+			perSecond: 100,
+			requests:  []float64{100, 10},
+			minimum:   100 * time.Millisecond,
+		},
+		{
+			name:      "requests larger than the bucket pay off their debt",
+			perSecond: 100,
+			requests:  []float64{120, 1},
+			minimum:   200 * time.Millisecond,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			limiter := NewRateLimiter(subtest.perSecond)
+			start := time.Now()
+
+			// act
+			for _, tokens := range subtest.requests {
+				if err := limiter.Wait(context.Background(), tokens); err != nil {
+					test.Fatalf("test %q failed for %s", subtest.name, err)
+				}
+			}
+
+			// assert
+			if elapsed := time.Since(start); elapsed < subtest.minimum {
+				test.Errorf("test %q failed for %s < %s", subtest.name, elapsed, subtest.minimum)
+			}
+		})
+	}
+}
+
+func TestRateLimiterCancel(test *testing.T) {
+	// arrange
+	limiter := NewRateLimiter(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// act
+	err := limiter.Wait(ctx, 10)
+
+	// assert
+	if err == nil {
+		test.Errorf("test cancel failed for missing error")
+	}
+}
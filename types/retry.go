@@ -0,0 +1,194 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsretry "github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+// classes of transient errors a retry policy can retry on
+const (
+	ThrottlingErrors = "throttling" // request rate exceeded (e.g. s3 SlowDown, http 429)
+	ServerErrors     = "server"     // 5xx responses
+	NetworkErrors    = "network"    // connection resets, timeouts and cut off responses
+)
+
+var knownErrorClasses = map[string]interface{}{
+	ThrottlingErrors: nil,
+	ServerErrors:     nil,
+	NetworkErrors:    nil,
+}
+
+// fields left at their zero value are inherited from the parent policy (see Inherit)
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	RetryOn     []string
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    20 * time.Second,
+	RetryOn:     []string{ThrottlingErrors, ServerErrors, NetworkErrors},
+}
+
+// parses the "retry" map of the global, provider or operation config
+func MakeRetryPolicy(conf map[string]interface{}, owner string) (policy RetryPolicy, err error) {
+	if val, ok := conf["maxattempts"]; ok {
+		if policy.MaxAttempts, ok = ConfigInt(val); !ok || policy.MaxAttempts < 1 {
+			return RetryPolicy{}, fmt.Errorf("\"maxattempts\" of retry policy of %s is not a positive integer", owner)
+		}
+	}
+
+	if policy.BaseDelay, err = parseDelay(conf, "basedelay", owner); err != nil {
+		return RetryPolicy{}, err
+	}
+	if policy.MaxDelay, err = parseDelay(conf, "maxdelay", owner); err != nil {
+		return RetryPolicy{}, err
+	}
+
+	if val, ok := conf["retryon"]; ok {
+		rawClasses, ok := val.([]interface{})
+		if !ok {
+			return RetryPolicy{}, fmt.Errorf("\"retryon\" of retry policy of %s is not an array", owner)
+		}
+
+		// an empty list is valid and means nothing is retried
+		policy.RetryOn = make([]string, len(rawClasses))
+		for idx, rawClass := range rawClasses {
+			class, ok := rawClass.(string)
+			if _, known := knownErrorClasses[class]; !ok || !known {
+				return RetryPolicy{}, fmt.Errorf("unknown error class %v in retry policy of %s", rawClass, owner)
+			}
+			policy.RetryOn[idx] = class
+		}
+	}
+
+	return policy, nil
+}
+
+func parseDelay(conf map[string]interface{}, field string, owner string) (time.Duration, error) {
+	val, ok := conf[field]
+	if !ok {
+		return 0, nil
+	}
+
+	delay, ok := ConfigDuration(val)
+	if !ok {
+		return 0, fmt.Errorf("%q of retry policy of %s is not a positive duration string: %v", field, owner, val)
+	}
+
+	return delay, nil
+}
+
+// fills every unset field from the parent
+func (policy RetryPolicy) Inherit(parent RetryPolicy) RetryPolicy {
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = parent.MaxAttempts
+	}
+	if policy.BaseDelay == 0 {
+		policy.BaseDelay = parent.BaseDelay
+	}
+	if policy.MaxDelay == 0 {
+		policy.MaxDelay = parent.MaxDelay
+	}
+	if policy.RetryOn == nil {
+		policy.RetryOn = parent.RetryOn
+	}
+
+	return policy
+}
+
+// runs fn until it succeeds, fails with a non retryable error or the attempts are used up
+// returns the number of retries (not attempts) besides the last error
+func (policy RetryPolicy) Do(ctx context.Context, fn func() error) (retries int, err error) {
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || attempt >= policy.MaxAttempts || !policy.Retryable(err) {
+			return
+		}
+
+		delay := policy.backoff(attempt)
+		log.Printf("retrying in %s (attempt %d of %d failed): %s", delay, attempt, policy.MaxAttempts, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			// do not hide the actual failure behind the cancellation
+			return
+		}
+		retries++
+	}
+}
+
+// exponential backoff with full jitter so concurrent workers do not retry in lockstep
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.MaxDelay
+	if shift := attempt - 1; shift < 32 {
+		if exponential := policy.BaseDelay << shift; exponential > 0 && exponential < delay {
+			delay = exponential
+		}
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+func (policy RetryPolicy) Retryable(err error) bool {
+	// cancellation is never transient
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	for _, class := range policy.RetryOn {
+		if retryClassifiers[class](err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var retryClassifiers = map[string]func(error) bool{
+	ThrottlingErrors: func(err error) bool {
+		if (awsretry.ThrottleErrorCode{Codes: awsretry.DefaultThrottleErrorCodes}).IsErrorThrottle(err) == aws.TrueTernary {
+			return true
+		}
+		return httpStatus(err) == 429
+	},
+	ServerErrors: func(err error) bool {
+		return httpStatus(err) >= 500
+	},
+	NetworkErrors: func(err error) bool {
+		if (awsretry.RetryableConnectionError{}).IsErrorRetryable(err) == aws.TrueTernary {
+			return true
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+	},
+}
+
+func httpStatus(err error) int {
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		return statusErr.HTTPStatusCode()
+	}
+	// trino statements only carry the status as a field (failed queries themselves are answered with 200)
+	var trinoErr *trino.ErrQueryFailed
+	if errors.As(err, &trinoErr) {
+		return trinoErr.StatusCode
+	}
+
+	return 0
+}
@@ -0,0 +1,121 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+type statusError int
+
+func (err statusError) Error() string {
+	return fmt.Sprintf("http status %d", int(err))
+}
+
+func (err statusError) HTTPStatusCode() int {
+	return int(err)
+}
+
+func trinoError(status int) error {
+	return &trino.ErrQueryFailed{StatusCode: status, Reason: fmt.Errorf("failed")}
+}
+
+func TestRetryPolicyDo(test *testing.T) {
+	// arrange
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		RetryOn:     []string{ThrottlingErrors, ServerErrors},
+	}
+	testTabel := []struct {
+		name            string
+		errs            []error
+		expectedRetries int
+		expectedErr     bool
+	}{
+		{
+			name:            "success needs no retry",
+			errs:            []error{nil},
+			expectedRetries: 0,
+			expectedErr:     false,
+		},
+		{
+			name:            "throttled request succeeds on retry",
+			errs:            []error{statusError(429), statusError(503), nil},
+			expectedRetries: 2,
+			expectedErr:     false,
+		},
+		{
+			name:            "attempts are limited",
+			errs:            []error{statusError(500), statusError(500), statusError(500), nil},
+			expectedRetries: 2,
+			expectedErr:     true,
+		},
+		{
+			name:            "client errors are not retried",
+			errs:            []error{statusError(403), nil},
+			expectedRetries: 0,
+			expectedErr:     true,
+		},
+		{
+			name:            "failing trino requests are retried",
+			errs:            []error{trinoError(502), fmt.Errorf("register partition: %w", trinoError(429)), nil},
+			expectedRetries: 2,
+			expectedErr:     false,
+		},
+		{
+			name:            "failed trino queries are not retried",
+			errs:            []error{trinoError(200), nil},
+			expectedRetries: 0,
+			expectedErr:     true,
+		},
+		{
+			name:            "unconfigured classes are not retried",
+			errs:            []error{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), nil},
+			expectedRetries: 0,
+			expectedErr:     true,
+		},
+	}
+
+	for _, subtest := range testTabel {
+		test.Run(subtest.name, func(t *testing.T) {
+			call := 0
+			fn := func() error {
+				err := subtest.errs[call]
+				call++
+				return err
+			}
+
+			// act
+			retries, err := policy.Do(context.Background(), fn)
+
+			// assert
+			if retries != subtest.expectedRetries {
+				test.Errorf("test %q failed for %d != %d retries", subtest.name, retries, subtest.expectedRetries)
+			}
+			if (err != nil) != subtest.expectedErr {
+				test.Errorf("test %q failed for unexpected error %v", subtest.name, err)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyInherit(test *testing.T) {
+	// arrange
+	override, err := MakeRetryPolicy(map[string]interface{}{"maxattempts": float64(5), "retryon": []interface{}{}}, "test")
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	// act
+	result := override.Inherit(DefaultRetryPolicy)
+
+	// assert
+	if result.MaxAttempts != 5 || result.BaseDelay != DefaultRetryPolicy.BaseDelay || len(result.RetryOn) != 0 {
+		test.Errorf("test inherit failed for %+v", result)
+	}
+}
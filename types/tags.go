@@ -0,0 +1,35 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+type TagOptions struct {
+	// values are templates referencing partition columns by name, e.g. "{{.dt}}"
+	Tags   map[string]*template.Template
+	Remove []string
+	// the tags replace the whole tag set of an object instead of being merged into it
+	Replace bool
+}
+
+// renders the tag templates with the raw values of the partition columns
+func (options TagOptions) Render(resource RuntimeResource, partition Partition) (map[string]string, error) {
+	columns := make(map[string]string)
+	values := partition.GetValues()
+	for idx, spec := range resource.GetPartitionSpec() {
+		columns[spec.Name] = values[idx]
+	}
+
+	tags := make(map[string]string, len(options.Tags))
+	for key, valueTemplate := range options.Tags {
+		var value bytes.Buffer
+		if err := valueTemplate.Execute(&value, columns); err != nil {
+			return nil, fmt.Errorf("could not render tag %q: %w", key, err)
+		}
+		tags[key] = value.String()
+	}
+
+	return tags, nil
+}